@@ -167,6 +167,9 @@ func (msm *MsmTarget) groupForTempCI() map[YearMonth]GroupDataForTempCI {
 		return std_dev
 	}
 
+	// 代表年の選定には WindMode によらず16方位の風速を使用
+	W_spd16, _ := msm.wind16()
+
 	df_temp_m_mean := make(map[int]SubGroupDataForTempCI, 12)
 	df_temp_m_std := make(map[int]SubGroupDataForTempCI, 12)
 
@@ -177,7 +180,7 @@ func (msm *MsmTarget) groupForTempCI() map[YearMonth]GroupDataForTempCI {
 			DSWRF:  getMean(msm.DSWRF, index_m[m]),
 			MR:     getMean(msm.MR, index_m[m]),
 			APCP01: getMean(msm.APCP01, index_m[m]),
-			w_spd:  getMean(msm.W_spd, index_m[m]),
+			w_spd:  getMean(W_spd16, index_m[m]),
 		}
 		//月標準偏差
 		df_temp_m_std[m] = SubGroupDataForTempCI{
@@ -185,7 +188,7 @@ func (msm *MsmTarget) groupForTempCI() map[YearMonth]GroupDataForTempCI {
 			DSWRF:  getStdDev(msm.DSWRF, index_m[m]),
 			MR:     getStdDev(msm.MR, index_m[m]),
 			APCP01: getStdDev(msm.APCP01, index_m[m]),
-			w_spd:  getStdDev(msm.W_spd, index_m[m]),
+			w_spd:  getStdDev(W_spd16, index_m[m]),
 		}
 	}
 
@@ -198,7 +201,7 @@ func (msm *MsmTarget) groupForTempCI() map[YearMonth]GroupDataForTempCI {
 				DSWRF:  getMean(msm.DSWRF, index_ym[ym]),
 				MR:     getMean(msm.MR, index_ym[ym]),
 				APCP01: getMean(msm.APCP01, index_ym[ym]),
-				w_spd:  getMean(msm.W_spd, index_ym[ym]),
+				w_spd:  getMean(W_spd16, index_ym[ym]),
 			}
 		}
 	}
@@ -364,7 +367,8 @@ func (df *MsmTarget) FSCI() map[YearMonth]FSCIData {
 	g_ymd_mean.DSWRF_mean_ymd = getMeanForYearMonthGroupDay(df.DSWRF, &g_ymd_mean)
 	g_ymd_mean.MR_mean_ymd = getMeanForYearMonthGroupDay(df.MR, &g_ymd_mean)
 	g_ymd_mean.APCP01_mean_ymd = getMeanForYearMonthGroupDay(df.APCP01, &g_ymd_mean)
-	g_ymd_mean.w_spd_mean_ymd = getMeanForYearMonthGroupDay(df.W_spd, &g_ymd_mean)
+	W_spd16, _ := df.wind16()
+	g_ymd_mean.w_spd_mean_ymd = getMeanForYearMonthGroupDay(W_spd16, &g_ymd_mean)
 
 	// FS値,FS値の偏差,FS値の偏差が指定範囲内に入っているか
 	TMP_FS := g_ymd_mean.makeFS(func(msm *YMDMeanData, i int) float64 { return msm.TMP_mean_ymd[i] }, std_rate_TMP)
@@ -745,9 +749,16 @@ func (msmt *MsmTarget) patchRepYears(repYears []int) *MsmTarget {
 		NR:     []float64{},
 		h:      []float64{},
 		A:      []float64{},
+		IN0:    []float64{},
 		SR_est: []SolarRadiation{},
 		SR_msm: []SolarRadiation{},
 	}
+	if msmt.CS != nil {
+		EA.CS = []ClearSkyRadiation{}
+	}
+	EA.Horizon = msmt.Horizon
+	EA.Elevation = msmt.Elevation
+	EA.WindMode = msmt.WindMode
 
 	// 月日数
 	mdays := [...]int{31, 28, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
@@ -780,8 +791,12 @@ func (msmt *MsmTarget) patchRepYears(repYears []int) *MsmTarget {
 		EA.NR = append(EA.NR, df_temp.NR...)
 		EA.h = append(EA.h, df_temp.h...)
 		EA.A = append(EA.A, df_temp.A...)
+		EA.IN0 = append(EA.IN0, df_temp.IN0...)
 		EA.SR_est = append(EA.SR_est, df_temp.SR_est...)
 		EA.SR_msm = append(EA.SR_msm, df_temp.SR_msm...)
+		if EA.CS != nil {
+			EA.CS = append(EA.CS, df_temp.CS...)
+		}
 	}
 
 	for i := 0; i < len(EA.date); i++ {
@@ -793,7 +808,7 @@ func (msmt *MsmTarget) patchRepYears(repYears []int) *MsmTarget {
 		EA.smoothMonthGaps(v, msmt)
 	}
 
-	// ベクトル風速から16方位の風向風速を再計算
+	// ベクトル風速から風向風速を再計算 (WindMode による)
 	EA.WindVectorToDirAndSpeed()
 
 	return &EA
@@ -962,12 +977,14 @@ func (EA *MsmTarget) smoothMonthGaps(sm SmootingMonth, msmt *MsmTarget) {
 	APCP01 := [13]float64{}
 	h := [13]float64{}
 	A := [13]float64{}
+	IN0 := [13]float64{}
 	RH := [13]float64{}
 	Pw := [13]float64{}
 	NR := [13]float64{}
 	DT := [13]float64{}
 	AAA_est := [13]SolarRadiation{}
 	AAA_msm := [13]SolarRadiation{}
+	CS := [13]ClearSkyRadiation{}
 	// w_spd, w_dir はVGRD, UGRDから再計算する
 
 	for i := 0; i < 13; i++ {
@@ -982,6 +999,7 @@ func (EA *MsmTarget) smoothMonthGaps(sm SmootingMonth, msmt *MsmTarget) {
 		APCP01[i] = df_before.APCP01[i]*before_coef[i] + df_after.APCP01[i]*after_coef[i]
 		h[i] = df_before.h[i]*before_coef[i] + df_after.h[i]*after_coef[i]
 		A[i] = df_before.A[i]*before_coef[i] + df_after.A[i]*after_coef[i]
+		IN0[i] = df_before.IN0[i]*before_coef[i] + df_after.IN0[i]*after_coef[i]
 		RH[i] = df_before.RH[i]*before_coef[i] + df_after.RH[i]*after_coef[i]
 		Pw[i] = df_before.Pw[i]*before_coef[i] + df_after.Pw[i]*after_coef[i]
 		NR[i] = df_before.NR[i]*before_coef[i] + df_after.NR[i]*after_coef[i]
@@ -997,6 +1015,13 @@ func (EA *MsmTarget) smoothMonthGaps(sm SmootingMonth, msmt *MsmTarget) {
 				df_before.SR_msm[i].DT*before_coef[i] + df_after.SR_msm[i].DT*after_coef[i],
 			}
 		}
+		if EA.CS != nil {
+			CS[i] = ClearSkyRadiation{
+				df_before.CS[i].TH*before_coef[i] + df_after.CS[i].TH*after_coef[i],
+				df_before.CS[i].DN*before_coef[i] + df_after.CS[i].DN*after_coef[i],
+				df_before.CS[i].SH*before_coef[i] + df_after.CS[i].SH*after_coef[i],
+			}
+		}
 		DT[i] = df_before.DT[i]*before_coef[i] + df_after.DT[i]*after_coef[i]
 		// w_spd, w_dir はVGRD, UGRDから再計算する
 	}
@@ -1018,12 +1043,16 @@ func (EA *MsmTarget) smoothMonthGaps(sm SmootingMonth, msmt *MsmTarget) {
 		EA.APCP01[index] = APCP01[i]
 		EA.h[index] = h[i]
 		EA.A[index] = A[i]
+		EA.IN0[index] = IN0[i]
 		EA.RH[index] = RH[i]
 		EA.Pw[index] = Pw[i]
 		EA.DT[index] = DT[i]
 		EA.NR[index] = NR[i]
 		EA.SR_est[index] = AAA_est[i]
 		EA.SR_msm[index] = AAA_msm[i]
+		if EA.CS != nil {
+			EA.CS[index] = CS[i]
+		}
 		// w_spd, w_dir はVGRD, UGRDから再計算する
 	}
 }
@@ -3,6 +3,8 @@ package arcclimate
 import (
 	"math"
 	"time"
+
+	"github.com/udawtr/arcclimate-go/arcclimate/psychrometrics"
 )
 
 // MSMファイルから読み取ったデータ
@@ -121,11 +123,7 @@ func mixingRatio(PRES float64, TMP float64) float64 {
 // 絶対温度 T [K] から 飽和水蒸気圧 [hPa] を求める。
 // Wagner の式を用いています。
 func eSAT(T float64) float64 {
-	return math.Exp(-5800.2206/T+
-		1.3914993-0.048640239*T+
-		0.41764768*0.0001*T*T-
-		0.14452093*0.0000001*T*T*T+
-		6.5459673*math.Log(T)) / 100
+	return psychrometrics.SaturationPressure(T)
 }
 
 // 飽和水蒸気圧 eSAT [hPa] と 絶対温度 T [K] から 飽和水蒸気量 aT [g/m^3] を求める。
@@ -2,6 +2,7 @@ package arcclimate
 
 import (
 	"sort"
+	"strconv"
 	"time"
 )
 
@@ -23,10 +24,11 @@ type MsmTarget struct {
 	DSWRF []float64 //標準年の計算時に使用するDSWRF
 
 	//追加項目
-	W_spd []float64 //11.参照時刻時点の風速の瞬時値 (単位:m/s)
-	W_dir []float64 //12.参照時刻時点の風向の瞬時値 (単位:°)
+	W_spd []float64 //11.参照時刻時点の風速の瞬時値 (単位:m/s) (WindMode による)
+	W_dir []float64 //12.参照時刻時点の風向の瞬時値 (単位:°) (WindMode による)
 	h     []float64 //13.参照時刻時点の太陽高度角 (単位:°)
 	A     []float64 //14.参照時刻時点の太陽方位角 (単位:°)
+	IN0   []float64 //参照時刻の前1時間の大気外法線面日射量 (単位:MJ/m2)
 
 	NR []float64 //夜間放射量[MJ/m2]
 
@@ -39,6 +41,65 @@ type MsmTarget struct {
 	//直散分離用
 	SR_est []SolarRadiation //直散分離結果(推定日射量 DSWRF_est に基づく)
 	SR_msm []SolarRadiation //直散分離結果(日射量 DSWRF_msm に基づく)
+
+	//晴天日射量
+	CS []ClearSkyRadiation
+
+	//雲量 (単位:10分比)
+	CC []float64
+
+	//照度・天頂輝度
+	IL []Illuminance
+
+	//湿り空気の状態値
+	PS []Psychrometrics
+
+	//暑熱・温熱環境指標
+	TI []ThermalIndex
+
+	//降雪・積雪深・地表面反射率
+	SN []Snow
+
+	//基準蒸発散量 (単位:mm)
+	ET0 []float64
+
+	//日平均給水温度 (単位:℃)
+	WTR []float64
+
+	//地平線の仰角の分布(地形による遮蔽を考慮した場合)
+	Horizon *HorizonProfile
+
+	//16方位の風向風速
+	W_spd16 []float64 //16方位の風向との差で補正した風速 (単位:m/s)
+	W_dir16 []float64 //16方位に丸めた風向 (単位:°)
+
+	//風向風速の計算方法 (WindMode16 or WindModeContinuous, 空の場合は WindMode16)
+	WindMode string
+
+	//推計対象地点の標高 (単位:m)
+	Elevation float64
+
+	//設計用外気条件(計算した場合)
+	Design *DesignConditions
+
+	//月別の地中温度(計算した場合)
+	Ground *GroundTemperatures
+}
+
+// 推定日射量 DSWRF_est があればそれを、なければ日射量 DSWRF_msm を返します。
+func (msm *MsmTarget) dswrf() []float64 {
+	if msm.DSWRF_est != nil {
+		return msm.DSWRF_est
+	}
+	return msm.DSWRF_msm
+}
+
+// dswrf() で選択される日射量に対応する直散分離結果を返します。
+func (msm *MsmTarget) sr() []SolarRadiation {
+	if msm.DSWRF_est != nil {
+		return msm.SR_est
+	}
+	return msm.SR_msm
 }
 
 // 開始年 start_year から 終了年 end_year までのデータを抜き出して新しい構造体を作成します。
@@ -67,6 +128,7 @@ func (df_msm *MsmTarget) ExctactMsm(start_time time.Time, end_time time.Time) *M
 		APCP01: append([]float64{}, df_msm.APCP01[start_index:end_index+1]...),
 		h:      append([]float64{}, df_msm.h[start_index:end_index+1]...),
 		A:      append([]float64{}, df_msm.A[start_index:end_index+1]...),
+		IN0:    append([]float64{}, df_msm.IN0[start_index:end_index+1]...),
 		RH:     append([]float64{}, df_msm.RH[start_index:end_index+1]...),
 		Pw:     append([]float64{}, df_msm.Pw[start_index:end_index+1]...),
 		NR:     append([]float64{}, df_msm.NR[start_index:end_index+1]...),
@@ -89,6 +151,19 @@ func (df_msm *MsmTarget) ExctactMsm(start_time time.Time, end_time time.Time) *M
 	if df_msm.W_dir != nil {
 		msm.W_dir = append([]float64{}, df_msm.W_dir[start_index:end_index+1]...)
 	}
+	if df_msm.W_spd16 != nil {
+		msm.W_spd16 = append([]float64{}, df_msm.W_spd16[start_index:end_index+1]...)
+	}
+	if df_msm.W_dir16 != nil {
+		msm.W_dir16 = append([]float64{}, df_msm.W_dir16[start_index:end_index+1]...)
+	}
+	if df_msm.CS != nil {
+		msm.CS = append([]ClearSkyRadiation{}, df_msm.CS[start_index:end_index+1]...)
+	}
+	msm.Horizon = df_msm.Horizon
+	msm.Elevation = df_msm.Elevation
+	msm.Design = df_msm.Design
+	msm.WindMode = df_msm.WindMode
 
 	return &msm
 }
@@ -106,12 +181,17 @@ func (df_msm *MsmTarget) filterMsmLeapYear29th() *MsmTarget {
 	APCP01 := []float64{}
 	h := []float64{}
 	A := []float64{}
+	IN0 := []float64{}
 	RH := []float64{}
 	Pw := []float64{}
 	NR := []float64{}
 	DT := []float64{}
 	AAA_est := []SolarRadiation{}
 	AAA_msm := []SolarRadiation{}
+	var CS []ClearSkyRadiation
+	if df_msm.CS != nil {
+		CS = []ClearSkyRadiation{}
+	}
 	// w_spd := []float64{}
 	// w_dir := []float64{}
 
@@ -128,12 +208,16 @@ func (df_msm *MsmTarget) filterMsmLeapYear29th() *MsmTarget {
 			APCP01 = append(APCP01, df_msm.APCP01[i])
 			h = append(h, df_msm.h[i])
 			A = append(A, df_msm.A[i])
+			IN0 = append(IN0, df_msm.IN0[i])
 			RH = append(RH, df_msm.RH[i])
 			Pw = append(Pw, df_msm.Pw[i])
 			NR = append(NR, df_msm.NR[i])
 			DT = append(DT, df_msm.DT[i])
 			AAA_est = append(AAA_est, df_msm.SR_est[i])
 			AAA_msm = append(AAA_msm, df_msm.SR_msm[i])
+			if CS != nil {
+				CS = append(CS, df_msm.CS[i])
+			}
 			// w_spd = append(w_spd, df_msm.w_dir[i])
 			// w_dir = append(w_dir, df_msm.w_dir[i])
 		}
@@ -151,13 +235,105 @@ func (df_msm *MsmTarget) filterMsmLeapYear29th() *MsmTarget {
 		APCP01: APCP01,
 		h:      h,
 		A:      A,
+		IN0:    IN0,
 		RH:     RH,
 		Pw:     Pw,
 		NR:     NR,
 		DT:     DT,
 		SR_est: AAA_est,
 		SR_msm: AAA_msm,
+		CS:     CS,
 		// w_spd:     w_spd,
 		// w_dir:     w_dir,
 	}
 }
+
+// データに含まれる年の一覧を年の順に返します。
+func (msm *MsmTarget) years() []int {
+	years := []int{}
+	for i := 0; i < len(msm.date); i++ {
+		if i == 0 || msm.date[i].Year() != msm.date[i-1].Year() {
+			years = append(years, msm.date[i].Year())
+		}
+	}
+	return years
+}
+
+// 時刻を年ごとに月別と年間に分け、年の順に1月から12月、年間の順で f を呼び出します。
+// index はその月または年の時刻、month は月 (年間の場合は0) です。データのない月は呼び出しません。
+func (msm *MsmTarget) eachMonthAndYear(f func(index []int, month int)) {
+	var yearIndex []int
+	monthIndex := make([][]int, 12)
+	flush := func() {
+		for m := 0; m < 12; m++ {
+			if len(monthIndex[m]) > 0 {
+				f(monthIndex[m], m+1)
+			}
+		}
+		f(yearIndex, 0)
+		yearIndex = nil
+		monthIndex = make([][]int, 12)
+	}
+
+	for i := 0; i < len(msm.date); i++ {
+		if i > 0 && msm.date[i].Year() != msm.date[i-1].Year() {
+			flush()
+		}
+		m := int(msm.date[i].Month()) - 1
+		monthIndex[m] = append(monthIndex[m], i)
+		yearIndex = append(yearIndex, i)
+	}
+	if len(yearIndex) > 0 {
+		flush()
+	}
+}
+
+// 月別・年別の行の月を文字列で返します。年間の行 (月が0) は "annual" とします。
+func monthOrAnnual(month int) string {
+	if month == 0 {
+		return "annual"
+	}
+	return strconv.Itoa(month)
+}
+
+// 日時 t1, t2 が同じ日付かどうかを返します。
+func sameDay(t1 time.Time, t2 time.Time) bool {
+	return t1.Year() == t2.Year() && t1.YearDay() == t2.YearDay()
+}
+
+// 時刻 index (nil の場合は全時刻) を日付の変わり目で分け、日の順に f を呼び出します。
+// date はその日の0時、dayIndex はその日の時刻です。
+func (msm *MsmTarget) eachDay(index []int, f func(date time.Time, dayIndex []int)) {
+	n := len(msm.date)
+	if index != nil {
+		n = len(index)
+	}
+	at := func(k int) int {
+		if index == nil {
+			return k
+		}
+		return index[k]
+	}
+
+	start := 0
+	for k := 0; k < n; k++ {
+		if k == n-1 || !sameDay(msm.date[at(k)], msm.date[at(k+1)]) {
+			dayIndex := make([]int, 0, k+1-start)
+			for j := start; j <= k; j++ {
+				dayIndex = append(dayIndex, at(j))
+			}
+			t := msm.date[at(k)]
+			f(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), dayIndex)
+			start = k + 1
+		}
+	}
+}
+
+// 値 values の時刻 index の平均値を返します。
+func meanAt(values []float64, index []int) float64 {
+	var sum float64
+	for _, i := range index {
+		sum += values[i]
+	}
+	return sum / float64(len(index))
+}
@@ -0,0 +1,177 @@
+package arcclimate
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+//--------------------------------------
+// 適応的快適温度
+//--------------------------------------
+
+// 運転中の平均外気温度の指数加重の係数 α (EN 16798-1)
+const runningMeanAlpha = 0.8
+
+// 快適温度の範囲 (単位:℃)
+type ComfortBand struct {
+	Lower float64 //下限
+	Upper float64 //上限
+}
+
+// 適応的快適温度の区分名
+// EN 16798-1 のカテゴリー I, II, III と ASHRAE 55 の満足率90%, 80%の順
+var AdaptiveComfortCategories = []string{"EN_I", "EN_II", "EN_III", "ASHRAE_90", "ASHRAE_80"}
+
+// 日別の適応的快適温度に関するデータ
+type AdaptiveComfortDay struct {
+	Date  time.Time     //日付
+	TED   float64       //日平均外気温度 (単位:℃)
+	TRM   float64       //指数加重移動平均外気温度 (単位:℃)
+	Bands []ComfortBand //区分(AdaptiveComfortCategories)ごとの快適温度の範囲
+}
+
+// 月別の快適温度の範囲外の時間数
+type AdaptiveComfortHours struct {
+	YearMonth
+	Above []int //区分ごとの外気温度が上限を超える時間数
+	Below []int //区分ごとの外気温度が下限を下回る時間数
+}
+
+// 気温 TMP から日別の指数加重移動平均外気温度と適応的快適温度の範囲を求めます。
+// 移動平均は θrm = (1 - α) θed-1 + α θrm-1 (α = 0.8) とし、初日は当日の日平均外気温度で初期化します。
+func (msm *MsmTarget) AdaptiveComfort() []AdaptiveComfortDay {
+	days := []AdaptiveComfortDay{}
+
+	// 日平均外気温度
+	msm.eachDay(nil, func(date time.Time, index []int) {
+		days = append(days, AdaptiveComfortDay{Date: date, TED: meanAt(msm.TMP, index)})
+	})
+
+	// 指数加重移動平均外気温度と快適温度の範囲
+	for d := 0; d < len(days); d++ {
+		if d == 0 {
+			days[d].TRM = days[d].TED
+		} else {
+			days[d].TRM = (1-runningMeanAlpha)*days[d-1].TED + runningMeanAlpha*days[d-1].TRM
+		}
+		days[d].Bands = adaptiveComfortBands(days[d].TRM)
+	}
+
+	return days
+}
+
+// 指数加重移動平均外気温度 TRM [℃] における区分(AdaptiveComfortCategories)ごとの快適温度の範囲を求めます。
+// 適用範囲外の場合は NaN とします。
+//
+// EN 16798-1: 快適温度 0.33 TRM + 18.8
+// 上限は 10 < TRM < 30、下限は 15 < TRM < 30 の範囲で適用します。
+//
+// ASHRAE 55: 快適温度 0.31 TRM + 17.8
+// 10 <= TRM <= 33.5 の範囲で適用します。
+func adaptiveComfortBands(TRM float64) []ComfortBand {
+	bands := make([]ComfortBand, len(AdaptiveComfortCategories))
+
+	// EN 16798-1
+	Tc := 0.33*TRM + 18.8
+	for k, width := range [][2]float64{{3, 2}, {4, 3}, {5, 4}} {
+		bands[k] = ComfortBand{Lower: math.NaN(), Upper: math.NaN()}
+		if 10.0 < TRM && TRM < 30.0 {
+			bands[k].Upper = Tc + width[1]
+		}
+		if 15.0 < TRM && TRM < 30.0 {
+			bands[k].Lower = Tc - width[0]
+		}
+	}
+
+	// ASHRAE 55
+	Tc = 0.31*TRM + 17.8
+	for k, width := range []float64{2.5, 3.5} {
+		bands[3+k] = ComfortBand{Lower: math.NaN(), Upper: math.NaN()}
+		if 10.0 <= TRM && TRM <= 33.5 {
+			bands[3+k] = ComfortBand{Lower: Tc - width, Upper: Tc + width}
+		}
+	}
+
+	return bands
+}
+
+// 日別の快適温度の範囲 days を用いて、気温 TMP が上限を超える時間数と下限を下回る時間数を月別に集計します。
+func (msm *MsmTarget) AdaptiveComfortMonthlyHours(days []AdaptiveComfortDay) []AdaptiveComfortHours {
+	hours := []AdaptiveComfortHours{}
+
+	d := 0
+	for i := 0; i < len(msm.date); i++ {
+		t := msm.date[i]
+		for d < len(days)-1 && !sameDay(days[d].Date, t) {
+			d++
+		}
+
+		ym := YearMonth{t.Year(), int(t.Month())}
+		if len(hours) == 0 || hours[len(hours)-1].YearMonth != ym {
+			hours = append(hours, AdaptiveComfortHours{
+				YearMonth: ym,
+				Above:     make([]int, len(AdaptiveComfortCategories)),
+				Below:     make([]int, len(AdaptiveComfortCategories)),
+			})
+		}
+		h := &hours[len(hours)-1]
+
+		for k, band := range days[d].Bands {
+			if msm.TMP[i] > band.Upper {
+				h.Above[k]++
+			}
+			if msm.TMP[i] < band.Lower {
+				h.Below[k]++
+			}
+		}
+	}
+
+	return hours
+}
+
+// 日別の適応的快適温度をCSV形式で出力します。
+// 適用範囲外の快適温度の範囲は空欄とします。
+func AdaptiveComfortToCSV(buf *bytes.Buffer, days []AdaptiveComfortDay) {
+	buf.WriteString("date,TED,TRM")
+	for _, c := range AdaptiveComfortCategories {
+		buf.WriteString(fmt.Sprintf(",%s_lower,%s_upper", c, c))
+	}
+	buf.WriteString("\n")
+
+	writeFloat := func(v float64) {
+		buf.WriteString(",")
+		if !math.IsNaN(v) {
+			buf.WriteString(strconv.FormatFloat(v, 'f', 2, 64))
+		}
+	}
+	for _, day := range days {
+		buf.WriteString(day.Date.Format("2006-01-02"))
+		writeFloat(day.TED)
+		writeFloat(day.TRM)
+		for _, band := range day.Bands {
+			writeFloat(band.Lower)
+			writeFloat(band.Upper)
+		}
+		buf.WriteString("\n")
+	}
+}
+
+// 月別の快適温度の範囲外の時間数をCSV形式で出力します。
+func AdaptiveComfortHoursToCSV(buf *bytes.Buffer, hours []AdaptiveComfortHours) {
+	buf.WriteString("year,month")
+	for _, c := range AdaptiveComfortCategories {
+		buf.WriteString(fmt.Sprintf(",%s_above,%s_below", c, c))
+	}
+	buf.WriteString("\n")
+
+	for _, h := range hours {
+		buf.WriteString(fmt.Sprintf("%d,%d", h.Year, h.Month))
+		for k := range AdaptiveComfortCategories {
+			buf.WriteString(fmt.Sprintf(",%d,%d", h.Above[k], h.Below[k]))
+		}
+		buf.WriteString("\n")
+	}
+}
@@ -0,0 +1,52 @@
+package arcclimate
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 指数加重移動平均外気温度のテスト
+func Test_AdaptiveComfort(t *testing.T) {
+	// 日平均外気温度が 20, 25, 25 ℃の3日間
+	msm := &MsmTarget{}
+	for d, TMP := range []float64{20.0, 25.0, 25.0} {
+		for h := 0; h < 24; h++ {
+			msm.date = append(msm.date, time.Date(1970, 7, 1+d, h, 0, 0, 0, time.UTC))
+			msm.TMP = append(msm.TMP, TMP)
+		}
+	}
+	// 3日目の12時のみ高温
+	msm.TMP[48+12] = 35.0
+
+	days := msm.AdaptiveComfort()
+	assert.Equal(t, 3, len(days))
+	assert.InDelta(t, 20.0, days[0].TRM, 1.0e-9)
+	assert.InDelta(t, 20.0, days[1].TRM, 1.0e-9)
+	assert.InDelta(t, 0.2*25.0+0.8*20.0, days[2].TRM, 1.0e-9)
+
+	// EN 16798-1 カテゴリー II
+	assert.InDelta(t, 0.33*20.0+18.8+3, days[1].Bands[1].Upper, 1.0e-9)
+	assert.InDelta(t, 0.33*20.0+18.8-4, days[1].Bands[1].Lower, 1.0e-9)
+
+	hours := msm.AdaptiveComfortMonthlyHours(days)
+	assert.Equal(t, 1, len(hours))
+	// 3日目の12時のみ上限(28.73℃)を超え、1日目は終日下限(21.4℃)を下回る
+	assert.Equal(t, 1, hours[0].Above[1])
+	assert.Equal(t, 24, hours[0].Below[1])
+}
+
+// 適用範囲外の快適温度
+func Test_adaptiveComfortBands(t *testing.T) {
+	bands := adaptiveComfortBands(12.0)
+	assert.False(t, math.IsNaN(bands[0].Upper))
+	assert.True(t, math.IsNaN(bands[0].Lower))
+	assert.False(t, math.IsNaN(bands[3].Lower))
+
+	bands = adaptiveComfortBands(5.0)
+	for _, b := range bands {
+		assert.True(t, math.IsNaN(b.Lower) && math.IsNaN(b.Upper))
+	}
+}
@@ -9,10 +9,52 @@ import (
 	"github.com/hhkbp2/go-logging"
 )
 
+// 空間補間計算のオプション
+// 空文字列の項目は既定値として扱います。
+type InterpolateOptions struct {
+	// 晴天日射量の計算 "none"(既定値), "Ineichen" (Ineichen-Perez) または "Bird"
+	ModeClearSky string
+
+	// 月別リンケ混濁係数を記述したCSVファイルのパス ("Ineichen" の場合。空文字列の場合は可降水量から推定)
+	LinkeTurbidity string
+
+	// 地形による日射の遮蔽 "none"(既定値), "mesh" (3次メッシュの標高データから計算) または地平線の仰角を記述したCSVファイルのパス
+	Horizon string
+
+	// 風向風速の計算方法 "16"(既定値, 16方位に丸めた風向と補正後の風速) または "continuous" (連続的な風向とベクトルの大きさの風速)
+	// いずれの場合も16方位の風向風速は W_dir16, W_spd16 に格納されます。
+	WindMode string
+
+	// 設計用外気条件の計算 (標準年の計算をする場合も、検討期間全体のデータから計算します)
+	DesignConditions bool
+}
+
+// 既定のオプション
+var DefaultInterpolateOptions = InterpolateOptions{
+	ModeClearSky: "none",
+	Horizon:      "none",
+	WindMode:     WindMode16,
+}
+
+// 空文字列の項目を既定値で置き換えたオプションを返します。
+func (opts InterpolateOptions) withDefaults() InterpolateOptions {
+	if opts.ModeClearSky == "" {
+		opts.ModeClearSky = DefaultInterpolateOptions.ModeClearSky
+	}
+	if opts.Horizon == "" {
+		opts.Horizon = DefaultInterpolateOptions.Horizon
+	}
+	if opts.WindMode == "" {
+		opts.WindMode = DefaultInterpolateOptions.WindMode
+	}
+	return opts
+}
+
 // 緯度lat,経度lonで表される推計対象地点の周囲のMSMデータを利用して空間補間計算を行います。
 // 標準年の計算を行う場合は mode = "EA" とし、それ以外の場合は EA = "normal" とします。
 // 標準年データの検討に日射量の推計値を使用する場合は useEst = True とします。（使用しない場合2018年以降のデータのみで作成）
 // 出力する気象データの期間は開始年startYearから終了年endYearまでです。ただし、標準年の計算をする場合は、検討期間として解釈します。
+// 晴天日射量・地形による遮蔽・風向風速の計算方法・設計用外気条件は既定値とします。変更する場合は InterpolateWithOptions を使用します。
 func Interpolate(
 	lat float64,
 	lon float64,
@@ -25,6 +67,24 @@ func Interpolate(
 	useCache bool,
 	saveCache bool,
 	msmFileDir string) *MsmTarget {
+	return InterpolateWithOptions(lat, lon, startYear, endYear, modeEle, mode, useEst, modeSep, useCache, saveCache, msmFileDir, DefaultInterpolateOptions)
+}
+
+// オプション opts を指定して Interpolate と同様に空間補間計算を行います。
+func InterpolateWithOptions(
+	lat float64,
+	lon float64,
+	startYear int,
+	endYear int,
+	modeEle string,
+	mode string,
+	useEst bool,
+	modeSep string,
+	useCache bool,
+	saveCache bool,
+	msmFileDir string,
+	opts InterpolateOptions) *MsmTarget {
+	opts = opts.withDefaults()
 
 	log.Printf("データ読み込み")
 
@@ -40,15 +100,31 @@ func Interpolate(
 	log.Printf("補正計算")
 
 	// 周囲4地点のMSMデータフレームから標高補正したMSMデータフレームを作成
-	msm := PrportionalDivided(lat, lon, msms, ele, modeEle, modeSep)
+	msm := PrportionalDividedWithOptions(lat, lon, msms, ele, modeEle, modeSep, opts)
 
 	if mode == "normal" {
 		// 保存用に年月日をフィルタ
-		return msm.ExctactMsmYear(startYear, endYear)
+		res := msm.ExctactMsmYear(startYear, endYear)
+
+		// 設計用外気条件の計算
+		if opts.DesignConditions {
+			log.Printf("設計用外気条件の計算 %d-%d", startYear, endYear)
+			res.Design = res.CalcDesignConditions()
+		}
+		return res
 	} else if mode == "EA" {
+		// 設計用外気条件の計算
+		var design *DesignConditions
+		if opts.DesignConditions {
+			log.Printf("設計用外気条件の計算 %d-%d", startYear, endYear)
+			design = msm.ExctactMsmYear(startYear, endYear).CalcDesignConditions()
+		}
+
 		// 標準年の計算
 		log.Printf("標準年計算 %d-%d", startYear, endYear)
-		return msm.EA(startYear, endYear, useEst)
+		res := msm.EA(startYear, endYear, useEst)
+		res.Design = design
+		return res
 	}
 
 	panic(mode)
@@ -89,6 +165,20 @@ func PrportionalDivided(
 	eleMstr *ElevationMaster,
 	modeEle string,
 	modeSep string) *MsmTarget {
+	return PrportionalDividedWithOptions(lat, lon, msms, eleMstr, modeEle, modeSep, DefaultInterpolateOptions)
+}
+
+// オプション opts を指定して緯度 lat, 経度 lon の標高補正を行います。
+func PrportionalDividedWithOptions(
+	lat float64,
+	lon float64,
+	msms MsmDataSet,
+	eleMstr *ElevationMaster,
+	modeEle string,
+	modeSep string,
+	opts InterpolateOptions) *MsmTarget {
+	opts = opts.withDefaults()
+
 	logger := logging.GetLogger("arcclimate")
 	logger.Infof("補間計算を実行します")
 
@@ -110,6 +200,7 @@ func PrportionalDivided(
 	// 周囲のMSMの気象データを読み込んで標高補正後に按分する
 	log.Print("周囲のMSMの気象データを読み込んで標高補正後に按分する")
 	msm_target := msms.PrportionalDivided(weights, elevations, ele_target)
+	msm_target.Elevation = ele_target
 
 	// 相対湿度・飽和水蒸気圧・露点温度の計算
 	log.Print("相対湿度・飽和水蒸気圧・露点温度の計算")
@@ -119,6 +210,23 @@ func PrportionalDivided(
 	log.Print("水平面全天日射量の直散分離")
 	msm_target.SeparateSolarRadiation(lat, lon, ele_target, modeSep)
 
+	// 地形による日射の遮蔽
+	if opts.Horizon != "none" {
+		log.Print("地形による日射の遮蔽")
+		hp := NewHorizonProfile(lat, lon, ele_target, opts.Horizon, eleMstr)
+		msm_target.ApplyHorizon(hp)
+	}
+
+	// 晴天日射量の計算
+	if opts.ModeClearSky != "none" {
+		log.Print("晴天日射量の計算")
+		var lt *LinkeTurbidity
+		if opts.ModeClearSky == "Ineichen" && opts.LinkeTurbidity != "" {
+			lt = NewLinkeTurbidity(opts.LinkeTurbidity)
+		}
+		msm_target.CalcClearSky(lat, lon, ele_target, opts.ModeClearSky, lt)
+	}
+
 	// 大気放射量の単位をMJ/m2に換算
 	log.Print("大気放射量の単位をMJ/m2に換算")
 	msm_target.ConvertLdUnit()
@@ -127,8 +235,9 @@ func PrportionalDivided(
 	log.Print("夜間放射量の計算")
 	msm_target.CalcNocturnalRadiation()
 
-	// ベクトル風速から16方位の風向風速を計算
-	log.Print("ベクトル風速から16方位の風向風速を計算")
+	// ベクトル風速から風向風速を計算
+	log.Print("ベクトル風速から風向風速を計算")
+	msm_target.WindMode = opts.WindMode
 	msm_target.WindVectorToDirAndSpeed()
 
 	return msm_target
@@ -0,0 +1,18 @@
+package arcclimate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 空文字列の項目は既定値
+func Test_InterpolateOptions_withDefaults(t *testing.T) {
+	assert.Equal(t, DefaultInterpolateOptions, InterpolateOptions{}.withDefaults())
+
+	opts := InterpolateOptions{Horizon: "mesh", DesignConditions: true}.withDefaults()
+	assert.Equal(t, "none", opts.ModeClearSky)
+	assert.Equal(t, "mesh", opts.Horizon)
+	assert.Equal(t, WindMode16, opts.WindMode)
+	assert.True(t, opts.DesignConditions)
+}
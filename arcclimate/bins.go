@@ -0,0 +1,396 @@
+package arcclimate
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/udawtr/arcclimate-go/arcclimate/psychrometrics"
+)
+
+//--------------------------------------
+// ビンデータ
+//--------------------------------------
+
+// 既定の乾球温度のビン幅 [℃]
+const DefaultBinWidth = 2.0
+
+// 既定の重量絶対湿度のビン幅 [g/kg(DA)]
+const DefaultHumidityBinWidth = 1.0
+
+// 既定の時間帯の開始時刻 (0時から4時間ごと)
+var DefaultBinBlocks = []int{0, 4, 8, 12, 16, 20}
+
+// 乾球温度のビン
+type DryBulbBin struct {
+	Lower float64   //下限 [℃] (下限以上, 下限+ビン幅未満)
+	Hours []float64 //時間帯ごとの1年あたりの時間数 [h]
+	Total float64   //1年あたりの時間数 [h]
+	MCWB  float64   //平均同時湿球温度 [℃]
+	MCHR  float64   //平均同時重量絶対湿度 [g/kg(DA)]
+
+	BlockMCWB []float64 //時間帯ごとの平均同時湿球温度 [℃] (時間数が0の場合は NaN)
+	BlockMCHR []float64 //時間帯ごとの平均同時重量絶対湿度 [g/kg(DA)] (時間数が0の場合は NaN)
+}
+
+// 乾球温度のビンデータ
+type BinData struct {
+	Width  float64      //ビン幅 [℃]
+	Blocks []int        //時間帯の開始時刻
+	Years  int          //年数
+	Bins   []DryBulbBin //下限の昇順
+}
+
+// カンマ区切りの文字列 s から時間帯の開始時刻を昇順に並べて返します。 ex) 0,6,12,18
+// 開始時刻は0から23の重複のない整数とし、0が含まれない場合は0時からの時間帯を追加します。
+func ParseBinBlocks(s string) ([]int, error) {
+	blocks := []int{}
+	for _, item := range strings.Split(s, ",") {
+		h, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil || h < 0 || h > 23 {
+			return nil, fmt.Errorf("invalid bin block start hour %q", item)
+		}
+		for _, b := range blocks {
+			if b == h {
+				return nil, fmt.Errorf("duplicate bin block start hour %d", h)
+			}
+		}
+		blocks = append(blocks, h)
+	}
+	sort.Ints(blocks)
+	if blocks[0] != 0 {
+		blocks = append([]int{0}, blocks...)
+	}
+	return blocks, nil
+}
+
+// 時刻 hour が属する時間帯の番号を返します。
+func binBlock(hour int, blocks []int) int {
+	k := 0
+	for j, b := range blocks {
+		if hour >= b {
+			k = j
+		}
+	}
+	return k
+}
+
+// 時間帯 k の名称を返します。 ex) 00-04
+func binBlockName(k int, blocks []int) string {
+	end := 24
+	if k+1 < len(blocks) {
+		end = blocks[k+1]
+	}
+	return fmt.Sprintf("%02d-%02d", blocks[k], end)
+}
+
+// 気温 TMP, 重量絶対湿度 MR, 気圧 PRES から乾球温度のビンデータを作成します。
+// 時間数は時間帯 blocks (開始時刻) ごとに数え、複数年の場合は1年あたりの値とします。
+// 平均同時湿球温度・重量絶対湿度は各ビンの時間帯ごとの時刻の平均と全時刻の平均を求めます。
+//
+// Args:
+//
+//	width(float64): 乾球温度のビン幅 (℃)
+//	blocks([]int): 時間帯の開始時刻 (昇順, 0から始まる)
+func (msm *MsmTarget) CalcBins(width float64, blocks []int) *BinData {
+	bd := &BinData{Width: width, Blocks: blocks, Years: len(msm.years())}
+
+	type acc struct {
+		hours    []int
+		WB_block []float64
+		MR_block []float64
+		WB_sum   float64
+		MR_sum   float64
+		n        int
+	}
+	bins := map[int]*acc{}
+	for i := 0; i < len(msm.date); i++ {
+		k := int(math.Floor(msm.TMP[i] / width))
+		a, ok := bins[k]
+		if !ok {
+			a = &acc{
+				hours:    make([]int, len(blocks)),
+				WB_block: make([]float64, len(blocks)),
+				MR_block: make([]float64, len(blocks)),
+			}
+			bins[k] = a
+		}
+		b := binBlock(msm.date[i].Hour(), blocks)
+		WB := psychrometrics.WetBulb(msm.TMP[i], msm.MR[i], msm.PRES[i])
+		a.hours[b]++
+		a.WB_block[b] += WB
+		a.MR_block[b] += msm.MR[i]
+		a.WB_sum += WB
+		a.MR_sum += msm.MR[i]
+		a.n++
+	}
+
+	keys := make([]int, 0, len(bins))
+	for k := range bins {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+
+	years := float64(bd.Years)
+	for _, k := range keys {
+		a := bins[k]
+		bin := DryBulbBin{
+			Lower: float64(k) * width,
+			Hours: make([]float64, len(blocks)),
+			Total: float64(a.n) / years,
+			MCWB:  a.WB_sum / float64(a.n),
+			MCHR:  a.MR_sum / float64(a.n),
+
+			BlockMCWB: make([]float64, len(blocks)),
+			BlockMCHR: make([]float64, len(blocks)),
+		}
+		for j, n := range a.hours {
+			bin.Hours[j] = float64(n) / years
+			bin.BlockMCWB[j] = math.NaN()
+			bin.BlockMCHR[j] = math.NaN()
+			if n > 0 {
+				bin.BlockMCWB[j] = a.WB_block[j] / float64(n)
+				bin.BlockMCHR[j] = a.MR_block[j] / float64(n)
+			}
+		}
+		bd.Bins = append(bd.Bins, bin)
+	}
+
+	return bd
+}
+
+// ビンデータをCSV形式で出力します。
+// 列は ビンの下限・上限, 時間帯ごとの時間数, 合計時間数, 平均同時湿球温度, 平均同時重量絶対湿度,
+// 時間帯ごとの平均同時湿球温度, 時間帯ごとの平均同時重量絶対湿度 です。時間数が0の時間帯の平均値は空欄とします。
+func (bd *BinData) ToCSV(buf *bytes.Buffer) {
+	buf.WriteString("TMP_lower,TMP_upper")
+	for k := range bd.Blocks {
+		buf.WriteString(",hours_")
+		buf.WriteString(binBlockName(k, bd.Blocks))
+	}
+	buf.WriteString(",hours_total,MCWB,MCHR")
+	for _, name := range []string{",MCWB_", ",MCHR_"} {
+		for k := range bd.Blocks {
+			buf.WriteString(name)
+			buf.WriteString(binBlockName(k, bd.Blocks))
+		}
+	}
+	buf.WriteString("\n")
+
+	f := func(v float64, prec int) {
+		buf.WriteString(",")
+		if !math.IsNaN(v) {
+			buf.WriteString(strconv.FormatFloat(v, 'f', prec, 64))
+		}
+	}
+	for _, bin := range bd.Bins {
+		buf.WriteString(strconv.FormatFloat(bin.Lower, 'f', -1, 64))
+		buf.WriteString(",")
+		buf.WriteString(strconv.FormatFloat(bin.Lower+bd.Width, 'f', -1, 64))
+		for _, h := range bin.Hours {
+			f(h, 1)
+		}
+		f(bin.Total, 1)
+		f(bin.MCWB, 1)
+		f(bin.MCHR, 2)
+		for _, v := range bin.BlockMCWB {
+			f(v, 1)
+		}
+		for _, v := range bin.BlockMCHR {
+			f(v, 2)
+		}
+		buf.WriteString("\n")
+	}
+}
+
+//--------------------------------------
+// 乾球温度・重量絶対湿度の2次元ビン
+//--------------------------------------
+
+// 乾球温度と重量絶対湿度の2次元ビンデータ
+type JointBinData struct {
+	TMPWidth float64     //乾球温度のビン幅 [℃]
+	MRWidth  float64     //重量絶対湿度のビン幅 [g/kg(DA)]
+	Years    int         //年数
+	TMP      []float64   //乾球温度のビンの下限 [℃] (昇順)
+	MR       []float64   //重量絶対湿度のビンの下限 [g/kg(DA)] (昇順)
+	Hours    [][]float64 //乾球温度・重量絶対湿度のビンごとの1年あたりの時間数 [h]
+}
+
+// 気温 TMP と重量絶対湿度 MR から2次元ビンデータを作成します。
+// 時間数は複数年の場合は1年あたりの値とします。
+func (msm *MsmTarget) CalcJointBins(TMPWidth float64, MRWidth float64) *JointBinData {
+	jb := &JointBinData{TMPWidth: TMPWidth, MRWidth: MRWidth, Years: len(msm.years())}
+	if len(msm.date) == 0 {
+		return jb
+	}
+
+	tmin, tmax := math.MaxInt32, math.MinInt32
+	mmin, mmax := math.MaxInt32, math.MinInt32
+	for i := 0; i < len(msm.date); i++ {
+		t := int(math.Floor(msm.TMP[i] / TMPWidth))
+		m := int(math.Floor(msm.MR[i] / MRWidth))
+		tmin, tmax = minInt(tmin, t), maxInt(tmax, t)
+		mmin, mmax = minInt(mmin, m), maxInt(mmax, m)
+	}
+	for t := tmin; t <= tmax; t++ {
+		jb.TMP = append(jb.TMP, float64(t)*TMPWidth)
+		jb.Hours = append(jb.Hours, make([]float64, mmax-mmin+1))
+	}
+	for m := mmin; m <= mmax; m++ {
+		jb.MR = append(jb.MR, float64(m)*MRWidth)
+	}
+
+	w := 1 / float64(jb.Years)
+	for i := 0; i < len(msm.date); i++ {
+		t := int(math.Floor(msm.TMP[i]/TMPWidth)) - tmin
+		m := int(math.Floor(msm.MR[i]/MRWidth)) - mmin
+		jb.Hours[t][m] += w
+	}
+
+	return jb
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// 2次元ビンデータを、行を乾球温度、列を重量絶対湿度のビンの下限とする表のCSV形式で出力します。
+func (jb *JointBinData) ToCSV(buf *bytes.Buffer) {
+	buf.WriteString("TMP\\MR")
+	for _, m := range jb.MR {
+		buf.WriteString(",")
+		buf.WriteString(strconv.FormatFloat(m, 'f', -1, 64))
+	}
+	buf.WriteString("\n")
+
+	for k, t := range jb.TMP {
+		buf.WriteString(strconv.FormatFloat(t, 'f', -1, 64))
+		for _, h := range jb.Hours[k] {
+			buf.WriteString(",")
+			buf.WriteString(strconv.FormatFloat(h, 'f', 1, 64))
+		}
+		buf.WriteString("\n")
+	}
+}
+
+//--------------------------------------
+// 外気利用(エコノマイザー)の範囲
+//--------------------------------------
+
+// 乾球温度・重量絶対湿度の範囲
+// 各値が NaN の場合は制限しません。範囲は下限以上, 上限以下です。
+type Envelope struct {
+	Name   string
+	TMPMin float64 //乾球温度の下限 [℃]
+	TMPMax float64 //乾球温度の上限 [℃]
+	MRMin  float64 //重量絶対湿度の下限 [g/kg(DA)]
+	MRMax  float64 //重量絶対湿度の上限 [g/kg(DA)]
+}
+
+// 範囲を文字列 s から作成します。
+// s は 名称:乾球温度の下限:上限:重量絶対湿度の下限:上限 の形式で、空欄の値は制限しません。
+// ex) econ:-10:24::12
+func ParseEnvelope(s string) (Envelope, error) {
+	items := strings.Split(s, ":")
+	if len(items) != 5 || items[0] == "" {
+		return Envelope{}, fmt.Errorf("invalid envelope %q (name:TMP_min:TMP_max:MR_min:MR_max)", s)
+	}
+	e := Envelope{Name: items[0]}
+	values := make([]float64, 4)
+	for k, item := range items[1:] {
+		values[k] = math.NaN()
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(item), 64)
+		if err != nil {
+			return Envelope{}, fmt.Errorf("invalid envelope %q (name:TMP_min:TMP_max:MR_min:MR_max)", s)
+		}
+		values[k] = v
+	}
+	e.TMPMin, e.TMPMax, e.MRMin, e.MRMax = values[0], values[1], values[2], values[3]
+	return e, nil
+}
+
+// 気温 TMP [℃] と重量絶対湿度 MR [g/kg(DA)] が範囲内の場合 true を返します。
+func (e Envelope) Contains(TMP float64, MR float64) bool {
+	in := func(v float64, min float64, max float64) bool {
+		return (math.IsNaN(min) || v >= min) && (math.IsNaN(max) || v <= max)
+	}
+	return in(TMP, e.TMPMin, e.TMPMax) && in(MR, e.MRMin, e.MRMax)
+}
+
+// 範囲内の時間数
+type EnvelopeHours struct {
+	Envelope Envelope
+	Hours    []float64 //時間帯ごとの1年あたりの時間数 [h]
+	Total    float64   //1年あたりの時間数 [h]
+	Fraction float64   //全時間に対する割合 [-]
+}
+
+// 気温 TMP と重量絶対湿度 MR の時刻別値が範囲 envelopes 内にある時間数を時間帯 blocks ごとに数えます。
+// 時間数は複数年の場合は1年あたりの値とします。
+func (msm *MsmTarget) CountEnvelopeHours(envelopes []Envelope, blocks []int) []EnvelopeHours {
+	years := float64(len(msm.years()))
+	result := make([]EnvelopeHours, len(envelopes))
+	for k, e := range envelopes {
+		r := EnvelopeHours{Envelope: e, Hours: make([]float64, len(blocks))}
+		n := 0
+		for i := 0; i < len(msm.date); i++ {
+			if e.Contains(msm.TMP[i], msm.MR[i]) {
+				r.Hours[binBlock(msm.date[i].Hour(), blocks)] += 1 / years
+				n++
+			}
+		}
+		r.Total = float64(n) / years
+		if len(msm.date) > 0 {
+			r.Fraction = float64(n) / float64(len(msm.date))
+		}
+		result[k] = r
+	}
+	return result
+}
+
+// 範囲内の時間数をCSV形式で出力します。
+func EnvelopeHoursToCSV(buf *bytes.Buffer, result []EnvelopeHours, blocks []int) {
+	buf.WriteString("name,TMP_min,TMP_max,MR_min,MR_max")
+	for k := range blocks {
+		buf.WriteString(",hours_")
+		buf.WriteString(binBlockName(k, blocks))
+	}
+	buf.WriteString(",hours_total,fraction\n")
+
+	limit := func(v float64) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	for _, r := range result {
+		e := r.Envelope
+		buf.WriteString(strings.Join([]string{e.Name, limit(e.TMPMin), limit(e.TMPMax), limit(e.MRMin), limit(e.MRMax)}, ","))
+		for _, h := range r.Hours {
+			buf.WriteString(",")
+			buf.WriteString(strconv.FormatFloat(h, 'f', 1, 64))
+		}
+		buf.WriteString(",")
+		buf.WriteString(strconv.FormatFloat(r.Total, 'f', 1, 64))
+		buf.WriteString(",")
+		buf.WriteString(strconv.FormatFloat(r.Fraction, 'f', 3, 64))
+		buf.WriteString("\n")
+	}
+}
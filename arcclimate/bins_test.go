@@ -0,0 +1,117 @@
+package arcclimate
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 時間帯の開始時刻
+func Test_ParseBinBlocks(t *testing.T) {
+	blocks, err := ParseBinBlocks("12, 0,6,18")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 6, 12, 18}, blocks)
+	assert.Equal(t, "18-24", binBlockName(3, blocks))
+	assert.Equal(t, 2, binBlock(17, blocks))
+
+	blocks, err = ParseBinBlocks("8,20")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 8, 20}, blocks)
+
+	_, err = ParseBinBlocks("0,24")
+	assert.NotNil(t, err)
+	_, err = ParseBinBlocks("0,6,6")
+	assert.NotNil(t, err)
+}
+
+// 乾球温度のビンデータ
+func Test_CalcBins(t *testing.T) {
+	bd := designTestTarget(2011, 2012).CalcBins(2, DefaultBinBlocks)
+	assert.Equal(t, 2, bd.Years)
+
+	// 1年あたりの時間数の合計は365日分
+	var total float64
+	for _, bin := range bd.Bins {
+		total += bin.Total
+		var sum float64
+		for _, h := range bin.Hours {
+			sum += h
+		}
+		assert.InDelta(t, bin.Total, sum, 1e-9)
+		assert.InDelta(t, 8.0, bin.MCHR, 1e-9)
+
+		// 時間帯ごとの平均は時間数で重み付けすると全時刻の平均に一致
+		var WB float64
+		for j, h := range bin.Hours {
+			if h == 0 {
+				assert.True(t, math.IsNaN(bin.BlockMCWB[j]))
+				continue
+			}
+			assert.InDelta(t, 8.0, bin.BlockMCHR[j], 1e-9)
+			WB += bin.BlockMCWB[j] * h
+		}
+		assert.InDelta(t, bin.MCWB, WB/bin.Total, 1e-9)
+		if bin.Lower >= 12 {
+			// 不飽和の範囲では湿球温度は乾球温度より低い
+			assert.True(t, bin.MCWB < bin.Lower+2)
+		}
+	}
+	assert.InDelta(t, 365*24, total, 1e-9)
+	assert.Equal(t, 0.0, bd.Bins[0].Lower)
+	assert.Equal(t, 28.0, bd.Bins[len(bd.Bins)-1].Lower)
+
+	buf := bytes.NewBuffer(nil)
+	bd.ToCSV(buf)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, "TMP_lower,TMP_upper,hours_00-04,hours_04-08,hours_08-12,hours_12-16,hours_16-20,hours_20-24,hours_total,MCWB,MCHR,"+
+		"MCWB_00-04,MCWB_04-08,MCWB_08-12,MCWB_12-16,MCWB_16-20,MCWB_20-24,"+
+		"MCHR_00-04,MCHR_04-08,MCHR_08-12,MCHR_12-16,MCHR_16-20,MCHR_20-24", lines[0])
+	assert.Equal(t, 2+6+3+12, len(strings.Split(lines[1], ",")))
+	assert.Equal(t, len(bd.Bins)+1, len(lines))
+}
+
+// 2次元ビンデータと外気利用の範囲
+func Test_CalcJointBins(t *testing.T) {
+	msm := designTestTarget(2011, 2011)
+	jb := msm.CalcJointBins(5, 1)
+	assert.Equal(t, []float64{8}, jb.MR)
+	assert.Equal(t, []float64{0, 5, 10, 15, 20, 25}, jb.TMP)
+	var total float64
+	for _, row := range jb.Hours {
+		total += row[0]
+	}
+	assert.InDelta(t, 365*24, total, 1e-9)
+
+	buf := bytes.NewBuffer(nil)
+	jb.ToCSV(buf)
+	assert.True(t, strings.HasPrefix(buf.String(), "TMP\\MR,8\n0,"))
+
+	e, err := ParseEnvelope("econ::15::9")
+	assert.Nil(t, err)
+	assert.True(t, math.IsNaN(e.TMPMin))
+	assert.True(t, e.Contains(-20, 9))
+	assert.False(t, e.Contains(16, 9))
+	assert.False(t, e.Contains(10, 10))
+	_, err = ParseEnvelope("econ:1:2")
+	assert.NotNil(t, err)
+
+	// 範囲内の時間数はビンの時間数と一致
+	dry, _ := ParseEnvelope("dry::::7")
+	hours := msm.CountEnvelopeHours([]Envelope{e, dry}, DefaultBinBlocks)
+	var below15 float64
+	for _, bin := range msm.CalcBins(1, DefaultBinBlocks).Bins {
+		if bin.Lower < 15 {
+			below15 += bin.Total
+		}
+	}
+	assert.InDelta(t, below15, hours[0].Total, 1e-9)
+	assert.InDelta(t, below15/(365*24), hours[0].Fraction, 1e-9)
+	assert.Equal(t, 0.0, hours[1].Total)
+
+	buf.Reset()
+	EnvelopeHoursToCSV(buf, hours, DefaultBinBlocks)
+	assert.Contains(t, buf.String(), "\necon,,15,,9,")
+}
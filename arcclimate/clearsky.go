@@ -0,0 +1,412 @@
+package arcclimate
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//--------------------------------------
+// 晴天日射量の計算
+//--------------------------------------
+
+// 晴天日射量に関するデータ
+type ClearSkyRadiation struct {
+	TH float64 //晴天時の水平面全天日射量 (単位:MJ/m2)
+	DN float64 //晴天時の法線面直達日射量 (単位:MJ/m2)
+	SH float64 //晴天時の水平面天空日射量 (単位:MJ/m2)
+}
+
+// 推計対象地点の緯度 lat, 経度 lon, 標高 ele_target [m] における晴天日射量を計算します。
+// 晴天モデル mode_clearsky は "Ineichen" (Ineichen-Perez) または "Bird" を指定します。
+// "Ineichen" の場合は月別リンケ混濁係数 lt を用い、lt が nil の場合は MonthlyLinkeTurbidity で可降水量から推定します。
+// "Bird" の場合は lt を使用しません。
+// "Bird" の場合は地表面と大気の多重反射に積雪に応じた地表面反射率を用いるため、積雪を計算していなければ CalcSnow で計算します。
+// 太陽位置および大気外法線面日射量は SeparateSolarRadiation で計算済みである必要があります。
+func (msm_target *MsmTarget) CalcClearSky(
+	lat float64,
+	lon float64,
+	ele_target float64,
+	mode_clearsky string,
+	lt *LinkeTurbidity) {
+
+	l := len(msm_target.date)
+	msm_target.CS = make([]ClearSkyRadiation, l)
+
+	if mode_clearsky == "Ineichen" {
+		// 月別リンケ混濁係数
+		var TL [12]float64
+		if lt != nil {
+			TL = lt.Monthly(lat, lon)
+		} else {
+			log.Print("月別リンケ混濁係数を可降水量から推定します")
+			TL = msm_target.MonthlyLinkeTurbidity()
+		}
+
+		for i := 0; i < l; i++ {
+			month := msm_target.date[i].Month()
+			msm_target.CS[i] = clearSkyIneichen(
+				msm_target.h[i],
+				msm_target.IN0[i],
+				msm_target.PRES[i],
+				ele_target,
+				TL[month-1])
+		}
+	} else if mode_clearsky == "Bird" {
+		// 積雪に応じた地表面反射率
+		if msm_target.SN == nil {
+			msm_target.CalcSnow()
+		}
+
+		for i := 0; i < l; i++ {
+			// 可降水量 [cm]
+			w := precipitableWater(msm_target.TMP[i], msm_target.Pw[i])
+
+			msm_target.CS[i] = clearSkyBird(
+				msm_target.h[i],
+				msm_target.IN0[i],
+				msm_target.PRES[i],
+				w,
+				msm_target.albedo(i, GroundAlbedo))
+		}
+	} else {
+		panic(mode_clearsky)
+	}
+}
+
+// 日射量 DSWRF [MJ/m2] と晴天時の水平面全天日射量 TH_cs [MJ/m2] から晴天指数 [-] を求めます。
+// 晴天日射量がほぼ0となる夜間や日出・日没時は計算できないため、NaNを返します。
+func clearSkyIndex(DSWRF float64, TH_cs float64) float64 {
+	if math.IsNaN(DSWRF) || TH_cs < 0.01 {
+		return math.NaN()
+	}
+	return DSWRF / TH_cs
+}
+
+// 太陽高度 h [deg] から相対エアマス [-] を求めます。
+// Kasten and Young (1989) の式を用いています。
+func relativeAirMass(h float64) float64 {
+	z := 90.0 - h
+	return 1.0 / (math.Cos(degreeToRad(z)) + 0.50572*math.Pow(96.07995-z, -1.6364))
+}
+
+// Ineichen-Perez の晴天モデル
+//
+// 参照)
+//
+//	Ineichen P, Perez R. A new airmass independent formulation for the Linke turbidity coefficient.
+//	Solar Energy 2002; 73(3): 151-157.
+//
+// Args:
+//
+//	h(float64): 太陽高度角 (deg)
+//	IN0(float64): 大気外法線面日射量 (MJ/m2)
+//	PRES(float64): 気圧 (Pa)
+//	ALT(float64): 標高 (m)
+//	TL(float64): リンケ混濁係数 (-)
+func clearSkyIneichen(h float64, IN0 float64, PRES float64, ALT float64, TL float64) ClearSkyRadiation {
+	if h <= 0.0 {
+		return ClearSkyRadiation{}
+	}
+
+	Sinh := math.Sin(degreeToRad(h))
+
+	// 絶対エアマス
+	AM := relativeAirMass(h) * PRES / 101325
+
+	fh1 := math.Exp(-ALT / 8000)
+	fh2 := math.Exp(-ALT / 1250)
+	cg1 := 5.09e-5*ALT + 0.868
+	cg2 := 3.92e-5*ALT + 0.0387
+
+	// 水平面全天日射量
+	TH := cg1 * IN0 * Sinh * math.Exp(-cg2*AM*(fh1+fh2*(TL-1))) * math.Exp(0.01*math.Pow(AM, 1.8))
+	TH = math.Max(TH, 0.0)
+
+	// 法線面直達日射量
+	b := 0.664 + 0.163/fh1
+	DN1 := b * IN0 * math.Exp(-0.09*AM*(TL-1))
+	DN2 := TH * math.Max(0.0, (1-(0.1-0.2*math.Exp(-TL))/(0.1+0.882/fh1))/Sinh)
+	DN := math.Max(0.0, math.Min(DN1, DN2))
+
+	// 水平面天空日射量
+	SH := math.Max(0.0, TH-DN*Sinh)
+
+	return ClearSkyRadiation{TH: TH, DN: DN, SH: SH}
+}
+
+// Bird の晴天モデル
+//
+// 参照)
+//
+//	Bird R, Hulstrom R. A simplified clear sky model for direct and diffuse insolation on horizontal surfaces.
+//	SERI/TR-642-761, 1981.
+//
+// オゾン量 0.3 cm、エアロゾル光学的厚さ 0.1 (500nm) / 0.15 (380nm) を仮定しています。
+//
+// Args:
+//
+//	h(float64): 太陽高度角 (deg)
+//	IN0(float64): 大気外法線面日射量 (MJ/m2)
+//	PRES(float64): 気圧 (Pa)
+//	w(float64): 可降水量 (cm)
+//	albedo(float64): 地表面反射率 (-)
+func clearSkyBird(h float64, IN0 float64, PRES float64, w float64, albedo float64) ClearSkyRadiation {
+	if h <= 0.0 {
+		return ClearSkyRadiation{}
+	}
+
+	const ozone = 0.3      // オゾン量 [cm]
+	const asymmetry = 0.85 // 前方散乱の割合
+
+	Sinh := math.Sin(degreeToRad(h))
+
+	AM := relativeAirMass(h)
+	AMp := AM * PRES / 101325
+
+	// レイリー散乱
+	T_rayleigh := math.Exp(-0.0903 * math.Pow(AMp, 0.84) * (1.0 + AMp - math.Pow(AMp, 1.01)))
+
+	// オゾン吸収
+	AM_o3 := ozone * AM
+	T_ozone := 1.0 - 0.1611*AM_o3*math.Pow(1.0+139.48*AM_o3, -0.3034) - 0.002715*AM_o3/(1.0+0.044*AM_o3+0.0003*AM_o3*AM_o3)
+
+	// 混合気体吸収
+	T_gases := math.Exp(-0.0127 * math.Pow(AMp, 0.26))
+
+	// 水蒸気吸収
+	AM_h2o := AM * w
+	T_water := 1.0 - 2.4959*AM_h2o/(math.Pow(1.0+79.034*AM_h2o, 0.6828)+6.385*AM_h2o)
+
+	// エアロゾル
+	tau_a := broadbandAOD()
+	T_aerosol := math.Exp(-math.Pow(tau_a, 0.873) * (1.0 + tau_a - math.Pow(tau_a, 0.7088)) * math.Pow(AM, 0.9108))
+	T_aa := 1.0 - 0.1*(1.0-AM+math.Pow(AM, 1.06))*(1.0-T_aerosol)
+	rs := 0.0685 + (1.0-asymmetry)*(1.0-T_aerosol/T_aa)
+
+	// 法線面直達日射量
+	DN := 0.9662 * IN0 * T_aerosol * T_water * T_gases * T_ozone * T_rayleigh
+
+	// 水平面散乱日射量
+	Ias := IN0 * Sinh * 0.79 * T_ozone * T_gases * T_water * T_aa *
+		(0.5*(1.0-T_rayleigh) + asymmetry*(1.0-T_aerosol/T_aa)) / (1.0 - AM + math.Pow(AM, 1.02))
+
+	// 水平面全天日射量
+	TH := (DN*Sinh + Ias) / (1.0 - albedo*rs)
+
+	return ClearSkyRadiation{TH: TH, DN: DN, SH: TH - DN*Sinh}
+}
+
+// エアロゾル光学的厚さ (Bird モデルおよびリンケ混濁係数の推定で仮定する値)
+const (
+	aod500 = 0.1  // 500nm
+	aod380 = 0.15 // 380nm
+)
+
+// 波長380nmと500nmのエアロゾル光学的厚さから広帯域のエアロゾル光学的厚さを求めます。(Bird and Hulstrom 1980)
+func broadbandAOD() float64 {
+	return 0.27583*aod380 + 0.35*aod500
+}
+
+// 気温 TMP [℃] と 水蒸気分圧 Pw [hPa] から可降水量 [cm] を求めます。
+// Gueymard (1994) の式を用いています。
+func precipitableWater(TMP float64, Pw float64) float64 {
+	T := TMP + 273.15
+	theta := T / 273.15
+
+	// 水蒸気の換算高さ [km]
+	Hv := 0.4976 + 1.5265*theta + math.Exp(13.6897*theta-14.9188*theta*theta*theta)
+
+	// 地表面の水蒸気密度 [g/m3]
+	rho_v := 216.7 * Pw / T
+
+	return math.Max(0.1*Hv*rho_v, 0.1)
+}
+
+//--------------------------------------
+// リンケ混濁係数
+//--------------------------------------
+
+// 可降水量 w [cm] からエアマス2のリンケ混濁係数 [-] を求めます。
+// Kasten (1996) の直達日射の式に、清浄乾燥大気・水蒸気・エアロゾル(Bird モデルと同じ値)の光学的厚さを与えています。
+//
+// 参照)
+//
+//	Kasten F. The Linke turbidity factor based on improved values of the integral Rayleigh optical thickness.
+//	Solar Energy 56(3), 239-244, 1996.
+//	Molineaux B, Ineichen P, O'Neill N. Equivalence of pyrheliometric and monochromatic aerosol optical depths
+//	at a single key wavelength. Applied Optics 37(30), 7008-7018, 1998.
+func linkeTurbidityFromWater(w float64) float64 {
+	const AM = 2.0
+
+	delta_cda := -0.101 + 0.235*math.Pow(AM, -0.16)            // 清浄乾燥大気
+	delta_w := 0.112 * math.Pow(AM, -0.55) * math.Pow(w, 0.34) // 水蒸気
+
+	return (9.4 + 0.9*AM) * (delta_cda + delta_w + broadbandAOD())
+}
+
+// 全期間の月別の平均の可降水量から月別リンケ混濁係数を推定します。
+// 可降水量は気温 TMP と水蒸気分圧 Pw から求めます。データのない月は NaN とします。
+func (msm *MsmTarget) MonthlyLinkeTurbidity() [12]float64 {
+	var sum [12]float64
+	var count [12]int
+	for i := 0; i < len(msm.date); i++ {
+		m := msm.date[i].Month() - 1
+		sum[m] += precipitableWater(msm.TMP[i], msm.Pw[i])
+		count[m]++
+	}
+
+	var TL [12]float64
+	for m := 0; m < 12; m++ {
+		TL[m] = math.NaN()
+		if count[m] > 0 {
+			TL[m] = linkeTurbidityFromWater(sum[m] / float64(count[m]))
+		}
+	}
+	return TL
+}
+
+// 月別リンケ混濁係数の格子データ
+//
+// 埋め込みのデータはありません。SoDa (Remund et al. 2003) などの出典が明らかな値を
+// 緯度,経度,1月から12月の値 の形式のCSVファイルとして与えてください。
+// 与えない場合は MonthlyLinkeTurbidity により可降水量から推定します。
+//
+// 参照)
+//
+//	Remund J, Wald L, Lefèvre M, Ranchin T, Page J. Worldwide Linke turbidity information.
+//	Proceedings of ISES Solar World Congress 2003.
+type LinkeTurbidity struct {
+	Lat []float64     // 格子点の緯度
+	Lon []float64     // 格子点の経度
+	TL  [][12]float64 // 格子点の月別リンケ混濁係数
+
+	dlat float64 // 格子間隔(緯度) [deg]
+	dlon float64 // 格子間隔(経度) [deg]
+}
+
+// 月別リンケ混濁係数の格子データをCSVファイル path から読み込みます。
+func NewLinkeTurbidity(path string) *LinkeTurbidity {
+	log.Printf("リンケ混濁係数をファイルから読み込みます %s", path)
+	file, err := os.Open(path)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	lt, err := ReadLinkeTurbidity(file)
+	if err != nil {
+		panic(err)
+	}
+	return lt
+}
+
+// 緯度,経度,1月から12月のリンケ混濁係数 を記述したCSVを読み込みます。
+// 数値でない行(ヘッダー等)と'#'で始まる行(出典等)は無視します。
+func ReadLinkeTurbidity(r io.Reader) (*LinkeTurbidity, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	lt := &LinkeTurbidity{}
+	for _, record := range records {
+		if len(record) < 14 {
+			continue
+		}
+		var values [14]float64
+		ok := true
+		for k := 0; k < 14; k++ {
+			values[k], err = strconv.ParseFloat(strings.TrimSpace(record[k]), 64)
+			if err != nil {
+				ok = false
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		var TL [12]float64
+		copy(TL[:], values[2:])
+		lt.Lat = append(lt.Lat, values[0])
+		lt.Lon = append(lt.Lon, values[1])
+		lt.TL = append(lt.TL, TL)
+	}
+
+	if len(lt.Lat) == 0 {
+		return nil, fmt.Errorf("リンケ混濁係数のデータがありません")
+	}
+
+	lt.dlat = minSpacing(lt.Lat)
+	lt.dlon = minSpacing(lt.Lon)
+
+	return lt, nil
+}
+
+// 座標の一覧 values の異なる値の最小の間隔を返します。値が1種類の場合は0を返します。
+func minSpacing(values []float64) float64 {
+	spacing := 0.0
+	for i := 0; i < len(values); i++ {
+		for j := i + 1; j < len(values); j++ {
+			d := math.Abs(values[i] - values[j])
+			if d > 1e-9 && (spacing == 0.0 || d < spacing) {
+				spacing = d
+			}
+		}
+	}
+	return spacing
+}
+
+// 緯度 lat, 経度 lon の月別リンケ混濁係数を周囲の格子点から距離の逆数で重みづけして求めます。
+// 格子の範囲外の場合は最も近い格子点の値とします。
+func (lt *LinkeTurbidity) Monthly(lat float64, lon float64) [12]float64 {
+	var TL [12]float64
+	var total_weight float64
+
+	nearest := 0
+	nearest_d := math.Inf(1)
+
+	for i := 0; i < len(lt.Lat); i++ {
+		dlat := lt.Lat[i] - lat
+		dlon := lt.Lon[i] - lon
+
+		d := math.Sqrt(dlat*dlat + dlon*dlon)
+		if d < 1e-9 {
+			return lt.TL[i]
+		}
+		if d < nearest_d {
+			nearest, nearest_d = i, d
+		}
+
+		// 格子間隔以内の格子点のみ使用
+		if math.Abs(dlat) >= lt.dlat || math.Abs(dlon) >= lt.dlon {
+			continue
+		}
+
+		w := 1.0 / d
+		for m := 0; m < 12; m++ {
+			TL[m] += w * lt.TL[i][m]
+		}
+		total_weight += w
+	}
+
+	if total_weight == 0.0 {
+		log.Printf("リンケ混濁係数の格子データの範囲外のため、最も近い格子点 (%f, %f) の値を使用します", lt.Lat[nearest], lt.Lon[nearest])
+		return lt.TL[nearest]
+	}
+
+	for m := 0; m < 12; m++ {
+		TL[m] /= total_weight
+	}
+
+	return TL
+}
@@ -0,0 +1,99 @@
+package arcclimate
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ineichen-Perez モデルのテスト
+// 天頂角30度、海面高度、リンケ混濁係数3の場合
+func Test_clearSkyIneichen(t *testing.T) {
+	IN0 := W_to_MJ(1367.0)
+	cs := clearSkyIneichen(60.0, IN0, 101325, 0.0, 3.0)
+
+	assert.InDelta(t, 910.4, MJ_to_W(cs.TH), 0.5)
+	assert.InDelta(t, cs.TH, cs.DN*math.Sin(degreeToRad(60.0))+cs.SH, 1.0e-9)
+
+	// 夜間は0
+	assert.Equal(t, ClearSkyRadiation{}, clearSkyIneichen(-5.0, IN0, 101325, 0.0, 3.0))
+}
+
+// Bird モデルのテスト
+func Test_clearSkyBird(t *testing.T) {
+	IN0 := W_to_MJ(1367.0)
+	cs := clearSkyBird(60.0, IN0, 101325, 1.5, GroundAlbedo)
+
+	assert.True(t, cs.TH > 0.0 && cs.TH < IN0)
+	assert.True(t, cs.DN > 0.0 && cs.SH > 0.0)
+	assert.InDelta(t, cs.TH, cs.DN*math.Sin(degreeToRad(60.0))+cs.SH, 1.0e-9)
+
+	// 積雪により地表面反射率が大きいと多重反射で天空日射量が増える
+	snow := clearSkyBird(60.0, IN0, 101325, 1.5, FreshSnowAlbedo)
+	assert.Equal(t, cs.DN, snow.DN)
+	assert.True(t, snow.SH > cs.SH)
+
+	// 夜間は0
+	assert.Equal(t, ClearSkyRadiation{}, clearSkyBird(0.0, IN0, 101325, 1.5, GroundAlbedo))
+}
+
+// リンケ混濁係数の読み込みと格子点からの補間
+func Test_LinkeTurbidity_Monthly(t *testing.T) {
+	lt, err := ReadLinkeTurbidity(strings.NewReader(`# source: test data
+lat,lon,1,2,3,4,5,6,7,8,9,10,11,12
+36,140,2,2,2,2,2,2,4,4,4,4,4,4
+36,141,3,3,3,3,3,3,5,5,5,5,5,5
+37,140,2,2,2,2,2,2,4,4,4,4,4,4
+37,141,3,3,3,3,3,3,5,5,5,5,5,5
+`))
+	assert.Nil(t, err)
+	assert.Len(t, lt.Lat, 4)
+
+	// 格子点
+	assert.Equal(t, lt.TL[1], lt.Monthly(36.0, 141.0))
+
+	// 格子の中央は4点の平均
+	TL := lt.Monthly(36.5, 140.5)
+	assert.InDelta(t, 2.5, TL[0], 1e-9)
+	assert.InDelta(t, 4.5, TL[11], 1e-9)
+
+	// 格子の範囲外は最も近い格子点の値
+	assert.Equal(t, lt.TL[3], lt.Monthly(40.0, 145.0))
+
+	// 1地点のみのデータ
+	lt, err = ReadLinkeTurbidity(strings.NewReader("35.7,139.7,3,3,3,3,3,3,3,3,3,3,3,3\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, 3.0, lt.Monthly(26.2, 127.7)[6])
+
+	_, err = ReadLinkeTurbidity(strings.NewReader("lat,lon\n"))
+	assert.NotNil(t, err)
+}
+
+// 可降水量からのリンケ混濁係数の推定
+func Test_MonthlyLinkeTurbidity(t *testing.T) {
+	// 可降水量 2.5 cm では約3.25
+	assert.InDelta(t, 3.25, linkeTurbidityFromWater(2.5), 0.01)
+	assert.True(t, linkeTurbidityFromWater(0.5) < linkeTurbidityFromWater(2.5))
+
+	msm := &MsmTarget{
+		date: []time.Time{
+			time.Date(2011, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2011, 1, 1, 1, 0, 0, 0, time.UTC),
+			time.Date(2011, 7, 1, 0, 0, 0, 0, time.UTC),
+		},
+		TMP: []float64{0.0, 0.0, 25.0},
+		Pw:  []float64{5.0, 5.0, 25.0},
+	}
+	TL := msm.MonthlyLinkeTurbidity()
+	assert.InDelta(t, linkeTurbidityFromWater(precipitableWater(0.0, 5.0)), TL[0], 1.0e-12)
+	assert.True(t, TL[6] > TL[0])
+	assert.True(t, math.IsNaN(TL[1]))
+}
+
+func Test_clearSkyIndex(t *testing.T) {
+	assert.InDelta(t, 0.5, clearSkyIndex(1.0, 2.0), 1.0e-12)
+	assert.True(t, math.IsNaN(clearSkyIndex(0.0, 0.0)))
+}
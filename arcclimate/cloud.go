@@ -0,0 +1,110 @@
+package arcclimate
+
+import (
+	"math"
+)
+
+//--------------------------------------
+// 雲量の推定
+//--------------------------------------
+
+// 昼間の推定のみを用いる太陽高度の下限 [deg]
+// これより低い太陽高度では夜間の推定と線形補間します。
+const cloudCoverDayAltitude = 10.0
+
+// 雲量 CC [10分比] を推定します。
+// 昼間は晴天指数(func_KT)から、夜間は大気放射量 Ld、気温 TMP、水蒸気分圧 Pw から求めた
+// 実効天空放射率から推定し、薄明時は両者を太陽高度に応じて補間します。
+// 太陽位置および大気外法線面日射量は SeparateSolarRadiation で計算済みである必要があります。
+func (msm *MsmTarget) CalcCloudCover() {
+	DSWRF := msm.dswrf()
+
+	msm.CC = make([]float64, len(msm.date))
+
+	for i := 0; i < len(msm.date); i++ {
+		h := msm.h[i]
+
+		// 夜間の推定
+		CC_night := cloudCoverFromLd(msm.Ld[i], msm.TMP[i], msm.Pw[i])
+		if h <= 0.0 {
+			msm.CC[i] = CC_night
+			continue
+		}
+
+		// 昼間の推定
+		CC_day := cloudCoverFromKT(DSWRF[i], msm.IN0[i], math.Sin(degreeToRad(h)))
+		if math.IsNaN(CC_day) {
+			msm.CC[i] = CC_night
+			continue
+		}
+
+		// 薄明時の補間
+		w := math.Min(h/cloudCoverDayAltitude, 1.0)
+		msm.CC[i] = w*CC_day + (1-w)*CC_night
+	}
+}
+
+// 水平面全天日射量 TH [MJ/m2]、大気外法線面日射量 IN0 [MJ/m2]、太陽高度角のサイン Sinh から
+// 雲量 [10分比] を推定します。
+// 晴天時の晴天指数を Kasten and Czeplak (1980) の晴天日射量 (910 Sinh - 30 [W/m2]) から求め、
+// 同式の雲量との関係 TH/TH0 = 1 - 0.75 (N/8)^3.4 を逆算しています。
+// 日射量が欠測の場合は NaN を返します。
+func cloudCoverFromKT(TH float64, IN0 float64, Sinh float64) float64 {
+	if math.IsNaN(TH) {
+		return math.NaN()
+	}
+
+	// 晴天時の水平面全天日射量
+	TH0 := W_to_MJ(910*Sinh - 30)
+	if TH0 <= 0.0 {
+		return math.NaN()
+	}
+
+	// 晴天指数と晴天時の晴天指数の比
+	KT := func_KT(TH, IN0, Sinh)
+	KT0 := func_KT(TH0, IN0, Sinh)
+	ratio := math.Min(KT/KT0, 1.0)
+
+	N := 8 * math.Pow((1-ratio)/0.75, 1/3.4) // 8分比
+	return math.Min(N*10/8, 10.0)
+}
+
+// 大気放射量 Ld [MJ/m2]、気温 TMP [℃]、水蒸気分圧 Pw [hPa] から雲量 [10分比] を推定します。
+// 実効天空放射率と Brutsaert (1975) の晴天時の天空放射率の比を、
+// EnergyPlus で用いられる Clark and Allen (1978) の雲量補正式
+// 1 + 0.0224 N - 0.0035 N^2 + 0.00028 N^3 により逆算しています。
+func cloudCoverFromLd(Ld float64, TMP float64, Pw float64) float64 {
+	T := TMP + 273.15
+
+	// 実効天空放射率
+	eps := MJ_to_W(Ld) / (sigma * pow4(T))
+
+	// 晴天時の天空放射率
+	eps_clr := 1.24 * math.Pow(Pw/T, 1.0/7.0)
+
+	ratio := eps / eps_clr
+
+	cloudFactor := func(N float64) float64 {
+		return 1 + 0.0224*N - 0.0035*N*N + 0.00028*N*N*N
+	}
+
+	if ratio <= 1.0 {
+		return 0.0
+	} else if ratio >= cloudFactor(10.0) {
+		return 10.0
+	}
+
+	// 雲量補正式は単調増加のため2分法で逆算
+	a := 0.0
+	b := 10.0
+	for b-a > 1e-6 {
+		N := (a + b) / 2
+		if cloudFactor(N) < ratio {
+			a = N
+		} else {
+			b = N
+		}
+	}
+
+	return (a + b) / 2
+}
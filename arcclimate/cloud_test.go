@@ -0,0 +1,44 @@
+package arcclimate
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 晴天指数からの雲量の推定
+func Test_cloudCoverFromKT(t *testing.T) {
+	Sinh := math.Sin(degreeToRad(60.0))
+	IN0 := W_to_MJ(1367.0)
+
+	// 晴天日射量と同じ日射量の場合は快晴
+	TH0 := W_to_MJ(910*Sinh - 30)
+	assert.InDelta(t, 0.0, cloudCoverFromKT(TH0, IN0, Sinh), 1.0e-9)
+
+	// 晴天日射量の25%の日射量の場合は全天曇り
+	assert.InDelta(t, 10.0, cloudCoverFromKT(TH0*0.25, IN0, Sinh), 1.0e-9)
+
+	// 欠測
+	assert.True(t, math.IsNaN(cloudCoverFromKT(math.NaN(), IN0, Sinh)))
+}
+
+// 大気放射量からの雲量の推定
+func Test_cloudCoverFromLd(t *testing.T) {
+	TMP := 10.0
+	Pw := 8.0
+	T := TMP + 273.15
+	eps_clr := 1.24 * math.Pow(Pw/T, 1.0/7.0)
+
+	// 晴天時の大気放射量
+	Ld_clr := W_to_MJ(eps_clr * sigma * pow4(T))
+	assert.InDelta(t, 0.0, cloudCoverFromLd(Ld_clr, TMP, Pw), 1.0e-6)
+
+	// 雲量5の大気放射量
+	N := 5.0
+	Ld_5 := Ld_clr * (1 + 0.0224*N - 0.0035*N*N + 0.00028*N*N*N)
+	assert.InDelta(t, 5.0, cloudCoverFromLd(Ld_5, TMP, Pw), 1.0e-5)
+
+	// 上限
+	assert.Equal(t, 10.0, cloudCoverFromLd(Ld_clr*1.3, TMP, Pw))
+}
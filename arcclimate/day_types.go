@@ -0,0 +1,193 @@
+package arcclimate
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+//--------------------------------------
+// 気象庁の階級別日数(夏日・真夏日・猛暑日・熱帯夜・冬日・真冬日)
+//--------------------------------------
+
+// 日最高気温・日最低気温による日の区分
+type DayType struct {
+	Name  string                              //CSVの列名
+	Label string                              //気象庁の用語
+	test  func(max float64, min float64) bool //該当する場合 true
+}
+
+// 気象庁の階級別日数の日の区分
+var DayTypes = []DayType{
+	{Name: "summer_day", Label: "夏日", test: func(max, min float64) bool { return max >= 25 }},
+	{Name: "hot_day", Label: "真夏日", test: func(max, min float64) bool { return max >= 30 }},
+	{Name: "extremely_hot_day", Label: "猛暑日", test: func(max, min float64) bool { return max >= 35 }},
+	{Name: "tropical_night", Label: "熱帯夜", test: func(max, min float64) bool { return min >= 25 }},
+	{Name: "winter_day", Label: "冬日", test: func(max, min float64) bool { return min < 0 }},
+	{Name: "ice_day", Label: "真冬日", test: func(max, min float64) bool { return max < 0 }},
+}
+
+// 日の区分の連続期間
+type DayTypeSpell struct {
+	Start time.Time //開始日
+	End   time.Time //終了日
+	Days  int       //日数
+}
+
+// 階級別日数の集計結果
+type DayTypeCounts struct {
+	Months  []YearMonth      //年月
+	Monthly [][]int          //年月・区分ごとの日数
+	Years   []int            //年
+	Annual  [][]int          //年・区分ごとの日数
+	Spells  [][]DayTypeSpell //年・区分ごとの最長の連続期間 (年をまたぐ場合は年内の期間)
+	Longest []DayTypeSpell   //区分ごとの全期間で最長の連続期間 (該当なしの場合は Days = 0)
+}
+
+// 日別の最高・最低気温
+type dailyExtreme struct {
+	date time.Time
+	max  float64 //日最高気温 [℃]
+	min  float64 //日最低気温 [℃]
+}
+
+// 気温 TMP から日最高・日最低気温を求めます。
+func (msm *MsmTarget) dailyExtremes() []dailyExtreme {
+	days := []dailyExtreme{}
+	msm.eachDay(nil, func(date time.Time, index []int) {
+		d := dailyExtreme{date: date, max: math.Inf(-1), min: math.Inf(1)}
+		for _, i := range index {
+			d.max = math.Max(d.max, msm.TMP[i])
+			d.min = math.Min(d.min, msm.TMP[i])
+		}
+		days = append(days, d)
+	})
+	return days
+}
+
+// 気温 TMP の日最高・日最低気温から気象庁の階級別日数を年月別・年別に数え、最長の連続期間を求めます。
+// 熱帯夜は日最低気温が25℃以上の日とします。連続期間は日付が連続する場合に限ります。
+func (msm *MsmTarget) CountDayTypes() *DayTypeCounts {
+	c := &DayTypeCounts{Longest: make([]DayTypeSpell, len(DayTypes))}
+
+	current := make([]DayTypeSpell, len(DayTypes)) //全期間の連続期間
+	yearly := make([]DayTypeSpell, len(DayTypes))  //年内の連続期間
+
+	var prev time.Time
+	for _, d := range msm.dailyExtremes() {
+		ym := YearMonth{d.date.Year(), int(d.date.Month())}
+		if len(c.Months) == 0 || c.Months[len(c.Months)-1] != ym {
+			c.Months = append(c.Months, ym)
+			c.Monthly = append(c.Monthly, make([]int, len(DayTypes)))
+		}
+		newYear := len(c.Years) == 0 || c.Years[len(c.Years)-1] != d.date.Year()
+		if newYear {
+			c.Years = append(c.Years, d.date.Year())
+			c.Annual = append(c.Annual, make([]int, len(DayTypes)))
+			c.Spells = append(c.Spells, make([]DayTypeSpell, len(DayTypes)))
+		}
+		consecutive := !prev.IsZero() && sameDay(prev.AddDate(0, 0, 1), d.date)
+		prev = d.date
+
+		y := len(c.Years) - 1
+		for k, dt := range DayTypes {
+			if !consecutive {
+				current[k] = DayTypeSpell{}
+			}
+			if newYear || !consecutive {
+				yearly[k] = DayTypeSpell{}
+			}
+			if !dt.test(d.max, d.min) {
+				current[k] = DayTypeSpell{}
+				yearly[k] = DayTypeSpell{}
+				continue
+			}
+
+			c.Monthly[len(c.Monthly)-1][k]++
+			c.Annual[y][k]++
+
+			current[k] = extendSpell(current[k], d.date)
+			yearly[k] = extendSpell(yearly[k], d.date)
+			if current[k].Days > c.Longest[k].Days {
+				c.Longest[k] = current[k]
+			}
+			if yearly[k].Days > c.Spells[y][k].Days {
+				c.Spells[y][k] = yearly[k]
+			}
+		}
+	}
+
+	return c
+}
+
+// 連続期間 s を日付 date まで延長します。
+func extendSpell(s DayTypeSpell, date time.Time) DayTypeSpell {
+	if s.Days == 0 {
+		s.Start = date
+	}
+	s.End = date
+	s.Days++
+	return s
+}
+
+// 年月別の階級別日数をCSV形式で出力します。
+func (c *DayTypeCounts) MonthlyToCSV(buf *bytes.Buffer) {
+	buf.WriteString("year,month")
+	for _, dt := range DayTypes {
+		buf.WriteString(",")
+		buf.WriteString(dt.Name)
+	}
+	buf.WriteString("\n")
+
+	for k, ym := range c.Months {
+		buf.WriteString(fmt.Sprintf("%d,%d", ym.Year, ym.Month))
+		for _, n := range c.Monthly[k] {
+			buf.WriteString(",")
+			buf.WriteString(strconv.Itoa(n))
+		}
+		buf.WriteString("\n")
+	}
+}
+
+// 年別の階級別日数と年内の最長の連続日数をCSV形式で出力します。
+func (c *DayTypeCounts) AnnualToCSV(buf *bytes.Buffer) {
+	buf.WriteString("year")
+	for _, dt := range DayTypes {
+		buf.WriteString(",")
+		buf.WriteString(dt.Name)
+	}
+	for _, dt := range DayTypes {
+		buf.WriteString(",longest_")
+		buf.WriteString(dt.Name)
+	}
+	buf.WriteString("\n")
+
+	for k, y := range c.Years {
+		buf.WriteString(strconv.Itoa(y))
+		for _, n := range c.Annual[k] {
+			buf.WriteString(",")
+			buf.WriteString(strconv.Itoa(n))
+		}
+		for _, s := range c.Spells[k] {
+			buf.WriteString(",")
+			buf.WriteString(strconv.Itoa(s.Days))
+		}
+		buf.WriteString("\n")
+	}
+}
+
+// 区分ごとの全期間で最長の連続期間をCSV形式で出力します。該当なしの場合は日付を空欄とします。
+func (c *DayTypeCounts) LongestToCSV(buf *bytes.Buffer) {
+	buf.WriteString("type,label,days,start,end\n")
+	for k, dt := range DayTypes {
+		s := c.Longest[k]
+		start, end := "", ""
+		if s.Days > 0 {
+			start = s.Start.Format("2006-01-02")
+			end = s.End.Format("2006-01-02")
+		}
+		buf.WriteString(fmt.Sprintf("%s,%s,%d,%s,%s\n", dt.Name, dt.Label, s.Days, start, end))
+	}
+}
@@ -0,0 +1,85 @@
+package arcclimate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 日最高・日最低気温が指定された試験用データ
+func dayTypesTestTarget(start time.Time, maxmin [][2]float64) *MsmTarget {
+	msm := &MsmTarget{}
+	for d, v := range maxmin {
+		for h := 0; h < 24; h++ {
+			TMP := v[1]
+			if h == 14 {
+				TMP = v[0]
+			}
+			msm.date = append(msm.date, start.AddDate(0, 0, d).Add(time.Duration(h)*time.Hour))
+			msm.TMP = append(msm.TMP, TMP)
+		}
+	}
+	return msm
+}
+
+// 階級別日数と連続期間
+func Test_CountDayTypes(t *testing.T) {
+	start := time.Date(2011, 12, 29, 0, 0, 0, 0, time.UTC)
+	c := dayTypesTestTarget(start, [][2]float64{
+		{36, 26}, // 2011-12-29 猛暑日・熱帯夜
+		{31, 20}, // 2011-12-30 真夏日
+		{-1, -5}, // 2011-12-31 真冬日
+		{-2, -6}, // 2012-01-01 真冬日
+		{-3, -4}, // 2012-01-02 真冬日
+		{5, -1},  // 2012-01-03 冬日
+	}).CountDayTypes()
+
+	assert.Equal(t, []YearMonth{{2011, 12}, {2012, 1}}, c.Months)
+	assert.Equal(t, []int{2, 2, 1, 1, 1, 1}, c.Monthly[0])
+	assert.Equal(t, []int{0, 0, 0, 0, 3, 2}, c.Monthly[1])
+	assert.Equal(t, []int{2011, 2012}, c.Years)
+	assert.Equal(t, c.Monthly[0], c.Annual[0])
+
+	// 夏日は2日連続、真冬日・冬日は年をまたいで連続
+	assert.Equal(t, 2, c.Longest[0].Days)
+	assert.Equal(t, 3, c.Longest[5].Days)
+	assert.Equal(t, time.Date(2011, 12, 31, 0, 0, 0, 0, time.UTC), c.Longest[5].Start)
+	assert.Equal(t, time.Date(2012, 1, 2, 0, 0, 0, 0, time.UTC), c.Longest[5].End)
+	assert.Equal(t, 4, c.Longest[4].Days)
+
+	// 年内の最長の連続日数
+	assert.Equal(t, 1, c.Spells[0][5].Days)
+	assert.Equal(t, 2, c.Spells[1][5].Days)
+	assert.Equal(t, 3, c.Spells[1][4].Days)
+
+	buf := bytes.NewBuffer(nil)
+	c.MonthlyToCSV(buf)
+	assert.Equal(t, "year,month,summer_day,hot_day,extremely_hot_day,tropical_night,winter_day,ice_day\n"+
+		"2011,12,2,2,1,1,1,1\n"+
+		"2012,1,0,0,0,0,3,2\n", buf.String())
+
+	buf.Reset()
+	c.AnnualToCSV(buf)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, "2012,0,0,0,0,3,2,0,0,0,0,3,2", lines[2])
+
+	buf.Reset()
+	c.LongestToCSV(buf)
+	assert.Contains(t, buf.String(), "ice_day,真冬日,3,2011-12-31,2012-01-02\n")
+	assert.Contains(t, buf.String(), "extremely_hot_day,猛暑日,1,2011-12-29,2011-12-29\n")
+}
+
+// 日付が連続しない場合は連続期間を区切る
+func Test_CountDayTypes_Gap(t *testing.T) {
+	a := dayTypesTestTarget(time.Date(1970, 7, 1, 0, 0, 0, 0, time.UTC), [][2]float64{{31, 20}, {31, 20}})
+	b := dayTypesTestTarget(time.Date(1970, 7, 5, 0, 0, 0, 0, time.UTC), [][2]float64{{31, 20}})
+	a.date = append(a.date, b.date...)
+	a.TMP = append(a.TMP, b.TMP...)
+
+	c := a.CountDayTypes()
+	assert.Equal(t, 3, c.Annual[0][1])
+	assert.Equal(t, 2, c.Longest[1].Days)
+}
@@ -0,0 +1,167 @@
+package arcclimate
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+//--------------------------------------
+// EnergyPlus 設計日(DDY)
+//--------------------------------------
+
+// 設計日
+type designDay struct {
+	name      string
+	month     int
+	dayType   string  //SummerDesignDay or WinterDesignDay
+	maxDB     float64 //最高乾球温度 [℃]
+	dbRange   float64 //乾球温度の日較差 [℃]
+	humType   string  //Wetbulb, Dewpoint or Enthalpy
+	hum       float64 //湿球温度・露点温度 [℃] または比エンタルピー [J/kg]
+	windSpeed float64 //風速 [m/s]
+	windDir   float64 //風向 [°]
+	taub      float64 //直達日射の光学的厚さ [-] (NaN の場合は ASHRAEClearSky)
+	taud      float64 //天空日射の光学的厚さ [-]
+	clearness float64 //晴天指数 [-] (ASHRAEClearSky の場合)
+}
+
+// EnergyPlus の Site:Location と SizingPeriod:DesignDay を記述した DDY 形式で出力します。
+// 設計用外気条件 Design が未計算の場合は計算します。
+// 暖房の設計日は最寒月21日、冷房の設計日は最暖月21日とし、冷房の日射は月別の光学的厚さ(ASHRAE Tau Model)によります。
+func (msm *MsmTarget) ToDDY(out *bytes.Buffer, lat float64, lon float64) {
+	dc := msm.Design
+	if dc == nil {
+		dc = msm.CalcDesignConditions()
+	}
+	name := locationName(lat, lon)
+
+	out.WriteString("! ArcClimate design day objects\n")
+	out.WriteString(fmt.Sprintf("! Design conditions computed from %d year(s) of hourly data by the method of the 2009 ASHRAE Handbook Fundamentals\n\n", dc.Years))
+
+	writeIDFObject(out, "Site:Location", [][2]string{
+		{name, "Name"},
+		{fmt.Sprintf("%.2f", lat), "Latitude {N+ S-}"},
+		{fmt.Sprintf("%.2f", lon), "Longitude {W- E+}"},
+		{"9.00", "Time Zone Relative to GMT {GMT+/-}"},
+		{fmt.Sprintf("%.2f", msm.Elevation), "Elevation {m}"},
+	})
+
+	h := dc.Heating
+	c := dc.Cooling
+	tau := ClearSkyTau{Taub: JSONFloat(math.NaN()), Taud: JSONFloat(math.NaN())}
+	if c.Month > 0 && len(dc.Tau) >= c.Month {
+		tau = dc.Tau[c.Month-1]
+	}
+
+	heating := func(n string, maxDB float64, humType string, hum float64) designDay {
+		return designDay{
+			name: fmt.Sprintf("%s Ann %s", name, n), month: h.Month, dayType: "WinterDesignDay",
+			maxDB: maxDB, dbRange: 0, humType: humType, hum: hum,
+			windSpeed: h.MCWS996, windDir: h.PCWD996,
+			taub: math.NaN(), taud: math.NaN(), clearness: 0,
+		}
+	}
+	cooling := func(n string, maxDB float64, humType string, hum float64) designDay {
+		return designDay{
+			name: fmt.Sprintf("%s Ann %s", name, n), month: c.Month, dayType: "SummerDesignDay",
+			maxDB: maxDB, dbRange: c.DBRange, humType: humType, hum: hum,
+			windSpeed: c.MCWS04, windDir: c.PCWD04,
+			taub: float64(tau.Taub), taud: float64(tau.Taud), clearness: 1,
+		}
+	}
+
+	days := []designDay{
+		heating("Htg 99.6% Condns DB", h.DB996, "Wetbulb", h.DB996),
+		heating("Htg 99% Condns DB", h.DB990, "Wetbulb", h.DB990),
+		heating("Hum_n 99.6% Condns DP=>MCDB", h.Humidification[0].MCDB, "Dewpoint", h.Humidification[0].DP),
+		heating("Hum_n 99% Condns DP=>MCDB", h.Humidification[1].MCDB, "Dewpoint", h.Humidification[1].DP),
+	}
+	for k, p := range []string{".4%", "1%", "2%"} {
+		days = append(days, cooling("Clg "+p+" Condns DB=>MWB", c.DB[k].DB, "Wetbulb", c.DB[k].MCWB))
+	}
+	for k, p := range []string{".4%", "1%", "2%"} {
+		days = append(days, cooling("Clg "+p+" Condns WB=>MDB", c.WB[k].MCDB, "Wetbulb", c.WB[k].WB))
+	}
+	for k, p := range []string{".4%", "1%", "2%"} {
+		days = append(days, cooling("Clg "+p+" Condns DP=>MDB", c.Dehumidification[k].MCDB, "Dewpoint", c.Dehumidification[k].DP))
+	}
+	for k, p := range []string{".4%", "1%", "2%"} {
+		days = append(days, cooling("Clg "+p+" Condns Enth=>MDB", c.Enthalpy[k].MCDB, "Enthalpy", c.Enthalpy[k].Enthalpy*1000))
+	}
+
+	for _, d := range days {
+		out.WriteString("\n")
+		d.toIDF(out, dc.Pressure)
+	}
+}
+
+// 設計日を SizingPeriod:DesignDay オブジェクトとして出力します。
+func (d designDay) toIDF(out *bytes.Buffer, pressure float64) {
+	f := func(v float64, prec int) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		return strconv.FormatFloat(v, 'f', prec, 64)
+	}
+
+	wetbulb, enthalpy := "", ""
+	if d.humType == "Enthalpy" {
+		enthalpy = f(d.hum, 0)
+	} else {
+		wetbulb = f(d.hum, 1)
+	}
+
+	solarModel, taub, taud, clearness := "ASHRAEClearSky", "", "", f(d.clearness, 2)
+	if !math.IsNaN(d.taub) && !math.IsNaN(d.taud) {
+		solarModel, taub, taud, clearness = "ASHRAETau", f(d.taub, 3), f(d.taud, 3), ""
+	}
+
+	writeIDFObject(out, "SizingPeriod:DesignDay", [][2]string{
+		{d.name, "Name"},
+		{strconv.Itoa(d.month), "Month"},
+		{"21", "Day of Month"},
+		{d.dayType, "Day Type"},
+		{f(d.maxDB, 1), "Maximum Dry-Bulb Temperature {C}"},
+		{f(d.dbRange, 1), "Daily Dry-Bulb Temperature Range {deltaC}"},
+		{"DefaultMultipliers", "Dry-Bulb Temperature Range Modifier Type"},
+		{"", "Dry-Bulb Temperature Range Modifier Day Schedule Name"},
+		{d.humType, "Humidity Condition Type"},
+		{wetbulb, "Wetbulb or DewPoint at Maximum Dry-Bulb {C}"},
+		{"", "Humidity Condition Day Schedule Name"},
+		{"", "Humidity Ratio at Maximum Dry-Bulb {kgWater/kgDryAir}"},
+		{enthalpy, "Enthalpy at Maximum Dry-Bulb {J/kg}"},
+		{"", "Daily Wet-Bulb Temperature Range {deltaC}"},
+		{f(pressure, 0), "Barometric Pressure {Pa}"},
+		{f(d.windSpeed, 1), "Wind Speed {m/s}"},
+		{f(d.windDir, 0), "Wind Direction {Degrees; N=0, S=180}"},
+		{"No", "Rain Indicator"},
+		{"No", "Snow Indicator"},
+		{"No", "Daylight Saving Time Indicator"},
+		{solarModel, "Solar Model Indicator"},
+		{"", "Beam Solar Day Schedule Name"},
+		{"", "Diffuse Solar Day Schedule Name"},
+		{taub, "ASHRAE Clear Sky Optical Depth for Beam Irradiance (taub) {dimensionless}"},
+		{taud, "ASHRAE Clear Sky Optical Depth for Diffuse Irradiance (taud) {dimensionless}"},
+		{clearness, "Sky Clearness"},
+	})
+}
+
+// IDF形式のオブジェクト class を出力します。fields は値と注釈の組です。
+func writeIDFObject(out *bytes.Buffer, class string, fields [][2]string) {
+	out.WriteString(class)
+	out.WriteString(",\n")
+	for k, field := range fields {
+		sep := ","
+		if k == len(fields)-1 {
+			sep = ";"
+		}
+		out.WriteString(fmt.Sprintf("  %-27s !- %s\n", field[0]+sep, field[1]))
+	}
+}
+
+// 緯度 lat, 経度 lon の地点名を返します。
+func locationName(lat float64, lon float64) string {
+	return fmt.Sprintf("ArcClimate %.2f_%.2f", lat, lon)
+}
@@ -0,0 +1,74 @@
+package arcclimate
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 晴天モデルの光学的厚さの推定
+func Test_fitClearSkyTau(t *testing.T) {
+	taub, taud := 0.45, 2.1
+	ab := 1.219 - 0.043*taub - 0.151*taud - 0.204*taub*taud
+	ad := 0.202 + 0.852*taub - 0.007*taud - 0.357*taub*taud
+
+	msm := &MsmTarget{}
+	start := time.Date(2011, 7, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 31*24; i++ {
+		hour := i % 24
+		h := 70 * math.Sin(math.Pi*float64(hour-6)/12)
+		IN0 := W_to_MJ(1367)
+		sr := SolarRadiation{}
+		if h > 0 {
+			m := relativeAirMass(h)
+			sr.DN = IN0 * math.Exp(-taub*math.Pow(m, ab))
+			sr.SH = IN0 * math.Exp(-taud*math.Pow(m, ad))
+		}
+		msm.date = append(msm.date, start.Add(time.Duration(i)*time.Hour))
+		msm.h = append(msm.h, h)
+		msm.IN0 = append(msm.IN0, IN0)
+		msm.SR_msm = append(msm.SR_msm, sr)
+	}
+
+	index := make([]int, len(msm.date))
+	for i := range index {
+		index[i] = i
+	}
+	b, d := fitClearSkyTau(msm, index)
+	assert.InDelta(t, taub, b, 0.002)
+	assert.InDelta(t, taud, d, 0.002)
+
+	// 日射量がない場合
+	b, d = fitClearSkyTau(&MsmTarget{date: msm.date}, index)
+	assert.True(t, math.IsNaN(b) && math.IsNaN(d))
+}
+
+// DDY形式の出力
+func Test_ToDDY(t *testing.T) {
+	msm := designTestTarget(2011, 2012)
+	msm.Elevation = 12.3
+	msm.Design = msm.CalcDesignConditions()
+
+	buf := bytes.NewBuffer(nil)
+	msm.ToDDY(buf, 35.0, 139.0)
+	s := buf.String()
+
+	assert.Equal(t, 1, strings.Count(s, "Site:Location,"))
+	assert.Equal(t, 16, strings.Count(s, "SizingPeriod:DesignDay,"))
+	assert.Contains(t, s, "ArcClimate 35.00_139.00 Ann Htg 99.6% Condns DB,")
+	assert.Contains(t, s, "12.30;")
+	assert.Contains(t, s, "WinterDesignDay,")
+	assert.Contains(t, s, "SummerDesignDay,")
+
+	// 日射量がない場合は ASHRAEClearSky
+	assert.NotContains(t, s, "ASHRAETau,")
+
+	// 同じ入力に対して同じ出力
+	buf2 := bytes.NewBuffer(nil)
+	msm.ToDDY(buf2, 35.0, 139.0)
+	assert.Equal(t, s, buf2.String())
+}
@@ -0,0 +1,194 @@
+package arcclimate
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//--------------------------------------
+// 度日・度時
+//--------------------------------------
+
+// 度日の定義
+// 暖房度日は日平均気温が限界温度 Threshold 以下の日の (基準温度 Base - 日平均気温) を、
+// 冷房度日は日平均気温が限界温度 Threshold 以上の日の (日平均気温 - 基準温度 Base) を積算します。
+// 基準温度と限界温度が等しい場合は、国際的に用いられる基準温度 Base の暖房度日(HDD)・冷房度日(CDD)と同じです。
+// 日本で用いられる暖房度日 D18-14 は Base = 18, Threshold = 14 の暖房度日です。
+type DegreeDaySpec struct {
+	Heating   bool    //暖房度日の場合 true, 冷房度日の場合 false
+	Base      float64 //基準温度(室温) [℃]
+	Threshold float64 //暖房・冷房限界温度 [℃]
+}
+
+// 度日の積算方法
+const (
+	DegreeDayDaily  = "daily"  //日平均気温による度日 [℃日]
+	DegreeDayHourly = "hourly" //時刻別の気温による度時 [℃h]
+)
+
+// 既定の暖房度日・冷房度日の基準温度 [℃]
+var (
+	DefaultHeatingDegreeDays = []string{"10", "14", "18"}
+	DefaultCoolingDegreeDays = []string{"18", "24"}
+)
+
+// 度日の定義を文字列 s から作成します。
+// s は基準温度のみ("18")または 基準温度-限界温度("18-14")の形式です。
+// 温度には符号を付けられます。 ex) "-5", "-5--10"
+func ParseDegreeDaySpec(s string, heating bool) (DegreeDaySpec, error) {
+	spec := DegreeDaySpec{Heating: heating}
+
+	// 基準温度の符号の後ろにある最初の "-" で区切る
+	t := strings.TrimSpace(s)
+	items := []string{t}
+	if k := strings.Index(strings.TrimLeft(t, "+-"), "-"); k >= 0 {
+		k += len(t) - len(strings.TrimLeft(t, "+-"))
+		items = []string{t[:k], t[k+1:]}
+	}
+
+	base, err := strconv.ParseFloat(strings.TrimSpace(items[0]), 64)
+	if err != nil {
+		return spec, fmt.Errorf("invalid degree-day base temperature %q", s)
+	}
+	spec.Base = base
+	spec.Threshold = base
+	if len(items) == 2 {
+		threshold, err := strconv.ParseFloat(strings.TrimSpace(items[1]), 64)
+		if err != nil {
+			return spec, fmt.Errorf("invalid degree-day threshold temperature %q", s)
+		}
+		spec.Threshold = threshold
+	}
+	return spec, nil
+}
+
+// 度日の名称を返します。 ex) HDD_18, HDD_18-14, CDD_24
+func (spec DegreeDaySpec) Name() string {
+	name := "CDD_"
+	if spec.Heating {
+		name = "HDD_"
+	}
+	name += strconv.FormatFloat(spec.Base, 'f', -1, 64)
+	if spec.Threshold != spec.Base {
+		name += "-" + strconv.FormatFloat(spec.Threshold, 'f', -1, 64)
+	}
+	return name
+}
+
+// 気温 T [℃] に対する度日(度時)の値を返します。
+func (spec DegreeDaySpec) value(T float64) float64 {
+	if spec.Heating {
+		if T <= spec.Threshold {
+			return math.Max(spec.Base-T, 0)
+		}
+	} else {
+		if T >= spec.Threshold {
+			return math.Max(T-spec.Base, 0)
+		}
+	}
+	return 0
+}
+
+// 度日の計算結果
+type DegreeDays struct {
+	Specs   []DegreeDaySpec //度日の定義
+	Method  string          //積算方法 daily or hourly
+	Months  []YearMonth     //年月
+	Monthly [][]float64     //年月・定義ごとの月積算値 [℃日] または [℃h]
+}
+
+// 気温 TMP から度日を計算し、年月別に積算します。
+// method が daily の場合は日平均気温による度日 [℃日] を、hourly の場合は時刻別の気温による度時 [℃h] を求めます。
+// 限界温度の判定も、daily の場合は日平均気温、hourly の場合は時刻別の気温によります。
+func (msm *MsmTarget) CalcDegreeDays(specs []DegreeDaySpec, method string) *DegreeDays {
+	dd := &DegreeDays{Specs: specs, Method: method}
+
+	add := func(ym YearMonth, T float64) {
+		if len(dd.Months) == 0 || dd.Months[len(dd.Months)-1] != ym {
+			dd.Months = append(dd.Months, ym)
+			dd.Monthly = append(dd.Monthly, make([]float64, len(specs)))
+		}
+		for k, spec := range specs {
+			dd.Monthly[len(dd.Monthly)-1][k] += spec.value(T)
+		}
+	}
+
+	switch method {
+	case DegreeDayDaily:
+		for _, d := range msm.dailyTemperatures() {
+			add(YearMonth{d.date.Year(), int(d.date.Month())}, d.TMP)
+		}
+	case DegreeDayHourly:
+		for i := 0; i < len(msm.date); i++ {
+			add(YearMonth{msm.date[i].Year(), int(msm.date[i].Month())}, msm.TMP[i])
+		}
+	default:
+		panic(method)
+	}
+
+	return dd
+}
+
+// 年別に積算した度日を返します。
+func (dd *DegreeDays) Annual() ([]int, [][]float64) {
+	years := []int{}
+	values := [][]float64{}
+	for k, ym := range dd.Months {
+		if len(years) == 0 || years[len(years)-1] != ym.Year {
+			years = append(years, ym.Year)
+			values = append(values, make([]float64, len(dd.Specs)))
+		}
+		for j := range dd.Specs {
+			values[len(values)-1][j] += dd.Monthly[k][j]
+		}
+	}
+	return years, values
+}
+
+// 月別の度日をCSV形式で出力します。
+func (dd *DegreeDays) MonthlyToCSV(buf *bytes.Buffer) {
+	buf.WriteString("year,month")
+	dd.writeHeader(buf)
+
+	for k, ym := range dd.Months {
+		buf.WriteString(fmt.Sprintf("%d,%d", ym.Year, ym.Month))
+		dd.writeValues(buf, dd.Monthly[k])
+	}
+}
+
+// 年別の度日をCSV形式で出力します。
+func (dd *DegreeDays) AnnualToCSV(buf *bytes.Buffer) {
+	buf.WriteString("year")
+	dd.writeHeader(buf)
+
+	years, values := dd.Annual()
+	for k, y := range years {
+		buf.WriteString(strconv.Itoa(y))
+		dd.writeValues(buf, values[k])
+	}
+}
+
+// CSVの度日の列名を出力します。度時の場合は列名の HDD, CDD を HDH, CDH とします。
+func (dd *DegreeDays) writeHeader(buf *bytes.Buffer) {
+	for _, spec := range dd.Specs {
+		name := spec.Name()
+		if dd.Method == DegreeDayHourly {
+			name = strings.Replace(strings.Replace(name, "HDD", "HDH", 1), "CDD", "CDH", 1)
+		}
+		buf.WriteString(",")
+		buf.WriteString(name)
+	}
+	buf.WriteString("\n")
+}
+
+// CSVの度日の値を出力します。
+func (dd *DegreeDays) writeValues(buf *bytes.Buffer, values []float64) {
+	for _, v := range values {
+		buf.WriteString(",")
+		buf.WriteString(strconv.FormatFloat(v, 'f', 1, 64))
+	}
+	buf.WriteString("\n")
+}
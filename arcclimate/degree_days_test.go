@@ -0,0 +1,98 @@
+package arcclimate
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 度日の定義
+func Test_ParseDegreeDaySpec(t *testing.T) {
+	spec, err := ParseDegreeDaySpec("18", true)
+	assert.Nil(t, err)
+	assert.Equal(t, DegreeDaySpec{Heating: true, Base: 18, Threshold: 18}, spec)
+	assert.Equal(t, "HDD_18", spec.Name())
+
+	spec, err = ParseDegreeDaySpec("18-14", true)
+	assert.Nil(t, err)
+	assert.Equal(t, DegreeDaySpec{Heating: true, Base: 18, Threshold: 14}, spec)
+	assert.Equal(t, "HDD_18-14", spec.Name())
+	assert.Equal(t, 0.0, spec.value(15))
+	assert.Equal(t, 4.0, spec.value(14))
+
+	spec, err = ParseDegreeDaySpec("24.5", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "CDD_24.5", spec.Name())
+	assert.Equal(t, 1.5, spec.value(26))
+
+	// 負の基準温度・限界温度
+	spec, err = ParseDegreeDaySpec("-5", true)
+	assert.Nil(t, err)
+	assert.Equal(t, DegreeDaySpec{Heating: true, Base: -5, Threshold: -5}, spec)
+	assert.Equal(t, "HDD_-5", spec.Name())
+
+	spec, err = ParseDegreeDaySpec("-5--10", true)
+	assert.Nil(t, err)
+	assert.Equal(t, DegreeDaySpec{Heating: true, Base: -5, Threshold: -10}, spec)
+	assert.Equal(t, "HDD_-5--10", spec.Name())
+
+	spec, err = ParseDegreeDaySpec("0--2", true)
+	assert.Nil(t, err)
+	assert.Equal(t, DegreeDaySpec{Heating: true, Base: 0, Threshold: -2}, spec)
+
+	_, err = ParseDegreeDaySpec("x", true)
+	assert.NotNil(t, err)
+	_, err = ParseDegreeDaySpec("18-", true)
+	assert.NotNil(t, err)
+	_, err = ParseDegreeDaySpec("--5", true)
+	assert.NotNil(t, err)
+}
+
+// 日平均気温による度日と時刻別の気温による度時
+func Test_CalcDegreeDays(t *testing.T) {
+	msm := &MsmTarget{}
+	for _, d := range []time.Time{
+		time.Date(2011, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2011, 1, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2011, 2, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2012, 2, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		for h := 0; h < 24; h++ {
+			msm.date = append(msm.date, d.Add(time.Duration(h)*time.Hour))
+			if d.Month() == 1 {
+				msm.TMP = append(msm.TMP, 10)
+			} else {
+				msm.TMP = append(msm.TMP, 20)
+			}
+		}
+	}
+
+	specs := []DegreeDaySpec{}
+	for _, s := range []string{"18", "18-14", "12-12"} {
+		spec, _ := ParseDegreeDaySpec(s, true)
+		specs = append(specs, spec)
+	}
+	spec, _ := ParseDegreeDaySpec("18", false)
+	specs = append(specs, spec)
+
+	dd := msm.CalcDegreeDays(specs, DegreeDayDaily)
+	assert.Equal(t, []YearMonth{{2011, 1}, {2011, 2}, {2012, 2}}, dd.Months)
+	assert.Equal(t, []float64{16, 16, 4, 0}, dd.Monthly[0])
+	assert.Equal(t, []float64{0, 0, 0, 2}, dd.Monthly[1])
+
+	years, annual := dd.Annual()
+	assert.Equal(t, []int{2011, 2012}, years)
+	assert.Equal(t, []float64{16, 16, 4, 2}, annual[0])
+
+	buf := bytes.NewBuffer(nil)
+	dd.AnnualToCSV(buf)
+	assert.Equal(t, "year,HDD_18,HDD_18-14,HDD_12,CDD_18\n2011,16.0,16.0,4.0,2.0\n2012,0.0,0.0,0.0,2.0\n", buf.String())
+
+	dd = msm.CalcDegreeDays(specs, DegreeDayHourly)
+	assert.Equal(t, []float64{384, 384, 96, 0}, dd.Monthly[0])
+	buf.Reset()
+	dd.MonthlyToCSV(buf)
+	assert.Equal(t, "year,month,HDH_18,HDH_18-14,HDH_12,CDH_18\n", buf.String()[:42])
+}
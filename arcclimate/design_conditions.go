@@ -0,0 +1,699 @@
+package arcclimate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/udawtr/arcclimate-go/arcclimate/psychrometrics"
+)
+
+//--------------------------------------
+// 設計用外気条件
+//--------------------------------------
+
+// 設計用外気条件
+// ASHRAE Handbook Fundamentals (2009) 14章の Climatic Design Information の手順に従います。
+type DesignConditions struct {
+	Years    int                      `json:"years"`    //集計年数
+	Pressure float64                  `json:"pressure"` //平均気圧 [Pa]
+	Heating  HeatingDesignConditions  `json:"heating"`  //暖房設計条件
+	Cooling  CoolingDesignConditions  `json:"cooling"`  //冷房設計条件
+	Extremes ExtremeDesignConditions  `json:"extremes"` //極値
+	Monthly  []MonthlyDesignCondition `json:"monthly"`  //月別の気温
+	Tau      []ClearSkyTau            `json:"tau"`      //月別の晴天モデルの光学的厚さ
+	TAC      TACDesignConditions      `json:"tac"`      //空気調和・衛生工学会のTAC温湿度
+}
+
+// 暖房設計条件
+type HeatingDesignConditions struct {
+	Month          int           `json:"month"`            //最寒月
+	DB996          float64       `json:"db_99.6"`          //99.6%乾球温度 [℃]
+	DB990          float64       `json:"db_99"`            //99%乾球温度 [℃]
+	Humidification [2]DPWithHR   `json:"humidification"`   //加湿用の99.6%, 99%露点温度
+	ColdestMonthWS [2]WSWithMCDB `json:"coldest_month_ws"` //最寒月の0.4%, 1%風速
+	MCWS996        float64       `json:"mcws_99.6"`        //99.6%乾球温度時の平均風速 [m/s]
+	PCWD996        float64       `json:"pcwd_99.6"`        //99.6%乾球温度時の最多風向 [°]
+}
+
+// 冷房設計条件
+type CoolingDesignConditions struct {
+	Month            int                 `json:"month"`            //最暖月
+	DBRange          float64             `json:"db_range"`         //最暖月の平均日較差 [℃]
+	DB               [3]DBWithMCWB       `json:"db"`               //0.4%, 1%, 2%乾球温度
+	WB               [3]WBWithMCDB       `json:"wb"`               //0.4%, 1%, 2%湿球温度
+	MCWS04           float64             `json:"mcws_0.4"`         //0.4%乾球温度時の平均風速 [m/s]
+	PCWD04           float64             `json:"pcwd_0.4"`         //0.4%乾球温度時の最多風向 [°]
+	Dehumidification [3]DPWithHR         `json:"dehumidification"` //除湿用の0.4%, 1%, 2%露点温度
+	Enthalpy         [3]EnthalpyWithMCDB `json:"enthalpy"`         //0.4%, 1%, 2%比エンタルピー
+	Hours8to4        float64             `json:"hours_8_to_4"`     //8時から16時で乾球温度が12.8℃から20.6℃の年間時間数
+}
+
+// 極値
+type ExtremeDesignConditions struct {
+	WS        [3]float64     `json:"ws"`          //1%, 2.5%, 5%風速 [m/s]
+	MaxWB     float64        `json:"max_wb"`      //最高湿球温度 [℃]
+	MeanMinDB float64        `json:"mean_min_db"` //年最低乾球温度の平均 [℃]
+	MeanMaxDB float64        `json:"mean_max_db"` //年最高乾球温度の平均 [℃]
+	StdMinDB  JSONFloat      `json:"std_min_db"`  //年最低乾球温度の標準偏差 [℃]
+	StdMaxDB  JSONFloat      `json:"std_max_db"`  //年最高乾球温度の標準偏差 [℃]
+	Return    []ReturnPeriod `json:"return"`      //再現期間ごとの極値
+}
+
+// 再現期間 N 年の最低・最高乾球温度 [℃]
+type ReturnPeriod struct {
+	N     int       `json:"n"`
+	MinDB JSONFloat `json:"min_db"`
+	MaxDB JSONFloat `json:"max_db"`
+}
+
+// 月別の気温
+type MonthlyDesignCondition struct {
+	Month   int     `json:"month"`
+	DBMean  float64 `json:"db_mean"`  //月平均乾球温度 [℃]
+	DBRange float64 `json:"db_range"` //平均日較差 [℃]
+	DBMax   float64 `json:"db_max"`   //月最高乾球温度 [℃]
+	DBMin   float64 `json:"db_min"`   //月最低乾球温度 [℃]
+}
+
+// ASHRAE の晴天モデル(ASHRAE Tau Model)の月別の光学的厚さ
+// 日射量がない場合は NaN とします。
+type ClearSkyTau struct {
+	Month int       `json:"month"`
+	Taub  JSONFloat `json:"taub"` //直達日射の光学的厚さ [-]
+	Taud  JSONFloat `json:"taud"` //天空日射の光学的厚さ [-]
+}
+
+// 空気調和・衛生工学会の超過確率(TAC)による設計用外気条件
+// 冷房は6月から9月、暖房は12月から3月の時刻別値を対象とし、超過確率2.5%, 5%の値を求めます。
+type TACDesignConditions struct {
+	CoolingDB       [2]float64 `json:"cooling_db"`       //冷房用乾球温度 [℃]
+	CoolingMR       [2]float64 `json:"cooling_mr"`       //冷房用重量絶対湿度 [g/kg(DA)]
+	CoolingEnthalpy [2]float64 `json:"cooling_enthalpy"` //冷房用比エンタルピー [kJ/kg(DA)]
+	HeatingDB       [2]float64 `json:"heating_db"`       //暖房用乾球温度 [℃]
+	HeatingMR       [2]float64 `json:"heating_mr"`       //暖房用重量絶対湿度 [g/kg(DA)]
+}
+
+// 乾球温度と同時発生の平均湿球温度
+type DBWithMCWB struct {
+	DB   float64 `json:"db"`
+	MCWB float64 `json:"mcwb"`
+}
+
+// 湿球温度と同時発生の平均乾球温度
+type WBWithMCDB struct {
+	WB   float64 `json:"wb"`
+	MCDB float64 `json:"mcdb"`
+}
+
+// 露点温度と重量絶対湿度 [g/kg(DA)]、同時発生の平均乾球温度
+type DPWithHR struct {
+	DP   float64 `json:"dp"`
+	HR   float64 `json:"hr"`
+	MCDB float64 `json:"mcdb"`
+}
+
+// 風速と同時発生の平均乾球温度
+type WSWithMCDB struct {
+	WS   float64 `json:"ws"`
+	MCDB float64 `json:"mcdb"`
+}
+
+// 比エンタルピー [kJ/kg(DA)] と同時発生の平均乾球温度
+type EnthalpyWithMCDB struct {
+	Enthalpy float64 `json:"enthalpy"`
+	MCDB     float64 `json:"mcdb"`
+}
+
+// JSON出力時に NaN を null とする数値
+type JSONFloat float64
+
+func (v JSONFloat) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(float64(v)) {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.FormatFloat(float64(v), 'f', -1, 64)), nil
+}
+
+// 設計用外気条件の再現期間 [年]
+var DesignReturnPeriods = []int{5, 10, 20, 50}
+
+// 気温 TMP, 重量絶対湿度 MR, 気圧 PRES, 水蒸気分圧 Pw, 風速 W_spd, 風向 W_dir から設計用外気条件を計算します。
+// 複数年のデータを対象とし、超過確率は全期間の時刻別値から求めます。
+func (msm *MsmTarget) CalcDesignConditions() *DesignConditions {
+	l := len(msm.date)
+
+	// 時刻別の露点温度・湿球温度・比エンタルピー
+	DP := make([]float64, l)
+	WB := make([]float64, l)
+	ENT := make([]float64, l)
+	var PRES_mean float64
+	for i := 0; i < l; i++ {
+		DP[i] = psychrometrics.DewPoint(msm.Pw[i])
+		WB[i] = psychrometrics.WetBulb(msm.TMP[i], msm.MR[i], msm.PRES[i])
+		ENT[i] = psychrometrics.Enthalpy(msm.TMP[i], msm.MR[i])
+		PRES_mean += msm.PRES[i]
+	}
+	PRES_mean /= float64(l)
+
+	// 露点温度 DP [℃] の平均気圧における重量絶対湿度 [g/kg(DA)]
+	HR := func(DP float64) float64 {
+		return psychrometrics.HumidityRatio(psychrometrics.SaturationPressure(DP+psychrometrics.ZeroCelsius), PRES_mean)
+	}
+
+	dc := &DesignConditions{Pressure: PRES_mean}
+
+	// 年別・月別・日別の集計
+	years := []int{}
+	yearMin := map[int]float64{}
+	yearMax := map[int]float64{}
+	monthIndex := make([][]int, 12)
+	var hours8to4 int
+	for i := 0; i < l; i++ {
+		t := msm.date[i]
+		y := t.Year()
+		if _, ok := yearMin[y]; !ok {
+			years = append(years, y)
+			yearMin[y] = msm.TMP[i]
+			yearMax[y] = msm.TMP[i]
+		}
+		yearMin[y] = math.Min(yearMin[y], msm.TMP[i])
+		yearMax[y] = math.Max(yearMax[y], msm.TMP[i])
+
+		m := int(t.Month()) - 1
+		monthIndex[m] = append(monthIndex[m], i)
+
+		if 8 <= t.Hour() && t.Hour() < 16 && 12.8 <= msm.TMP[i] && msm.TMP[i] <= 20.6 {
+			hours8to4++
+		}
+	}
+	dc.Years = len(years)
+
+	// 月別の気温
+	dc.Monthly = make([]MonthlyDesignCondition, 12)
+	for m := 0; m < 12; m++ {
+		dc.Monthly[m] = monthlyDesignCondition(msm, monthIndex[m])
+		dc.Monthly[m].Month = m + 1
+	}
+
+	// 月別の晴天モデルの光学的厚さ
+	dc.Tau = make([]ClearSkyTau, 12)
+	for m := 0; m < 12; m++ {
+		taub, taud := fitClearSkyTau(msm, monthIndex[m])
+		dc.Tau[m] = ClearSkyTau{Month: m + 1, Taub: JSONFloat(taub), Taud: JSONFloat(taud)}
+	}
+
+	// 最寒月・最暖月
+	coldest, hottest := 0, 0
+	for m := 0; m < 12; m++ {
+		if dc.Monthly[m].DBMean < dc.Monthly[coldest].DBMean {
+			coldest = m
+		}
+		if dc.Monthly[m].DBMean > dc.Monthly[hottest].DBMean {
+			hottest = m
+		}
+	}
+
+	all := make([]int, l)
+	for i := range all {
+		all[i] = i
+	}
+
+	//
+	// 暖房設計条件
+	//
+	h := &dc.Heating
+	h.Month = coldest + 1
+	h.DB996 = percentile(msm.TMP, all, 0.4)
+	h.DB990 = percentile(msm.TMP, all, 1.0)
+	for k, p := range []float64{0.4, 1.0} {
+		v := percentile(DP, all, p)
+		h.Humidification[k] = DPWithHR{DP: v, HR: HR(v), MCDB: coincidentMean(DP, v, msm.TMP, all)}
+	}
+	for k, p := range []float64{99.6, 99.0} {
+		v := percentile(msm.W_spd, monthIndex[coldest], p)
+		h.ColdestMonthWS[k] = WSWithMCDB{WS: v, MCDB: coincidentMean(msm.W_spd, v, msm.TMP, monthIndex[coldest])}
+	}
+	h.MCWS996 = coincidentMean(msm.TMP, h.DB996, msm.W_spd, all)
+	h.PCWD996 = coincidentMode(msm.TMP, h.DB996, msm.W_dir, all)
+
+	//
+	// 冷房設計条件
+	//
+	c := &dc.Cooling
+	c.Month = hottest + 1
+	c.DBRange = dc.Monthly[hottest].DBRange
+	for k, p := range []float64{99.6, 99.0, 98.0} {
+		db := percentile(msm.TMP, all, p)
+		c.DB[k] = DBWithMCWB{DB: db, MCWB: coincidentMean(msm.TMP, db, WB, all)}
+
+		wb := percentile(WB, all, p)
+		c.WB[k] = WBWithMCDB{WB: wb, MCDB: coincidentMean(WB, wb, msm.TMP, all)}
+
+		dp := percentile(DP, all, p)
+		c.Dehumidification[k] = DPWithHR{DP: dp, HR: HR(dp), MCDB: coincidentMean(DP, dp, msm.TMP, all)}
+
+		ent := percentile(ENT, all, p)
+		c.Enthalpy[k] = EnthalpyWithMCDB{Enthalpy: ent, MCDB: coincidentMean(ENT, ent, msm.TMP, all)}
+	}
+	c.MCWS04 = coincidentMean(msm.TMP, c.DB[0].DB, msm.W_spd, all)
+	c.PCWD04 = coincidentMode(msm.TMP, c.DB[0].DB, msm.W_dir, all)
+	c.Hours8to4 = float64(hours8to4) / float64(dc.Years)
+
+	//
+	// 極値
+	//
+	e := &dc.Extremes
+	for k, p := range []float64{99.0, 97.5, 95.0} {
+		e.WS[k] = percentile(msm.W_spd, all, p)
+	}
+	e.MaxWB = WB[0]
+	for i := 1; i < l; i++ {
+		e.MaxWB = math.Max(e.MaxWB, WB[i])
+	}
+
+	// 年の順に集計 (合計の順序を固定して出力を再現可能にする)
+	sort.Ints(years)
+	mins := make([]float64, 0, len(years))
+	maxs := make([]float64, 0, len(years))
+	for _, y := range years {
+		mins = append(mins, yearMin[y])
+		maxs = append(maxs, yearMax[y])
+	}
+	var stdMin, stdMax float64
+	e.MeanMinDB, stdMin = meanAndStd(mins)
+	e.MeanMaxDB, stdMax = meanAndStd(maxs)
+	e.StdMinDB = JSONFloat(stdMin)
+	e.StdMaxDB = JSONFloat(stdMax)
+
+	// Gumbel 分布(積率法)による再現期間 n 年の極値
+	e.Return = make([]ReturnPeriod, len(DesignReturnPeriods))
+	for k, n := range DesignReturnPeriods {
+		I := -math.Sqrt(6) / math.Pi * (0.5772 + math.Log(math.Log(float64(n)/float64(n-1))))
+		e.Return[k] = ReturnPeriod{
+			N:     n,
+			MinDB: JSONFloat(e.MeanMinDB - I*stdMin),
+			MaxDB: JSONFloat(e.MeanMaxDB + I*stdMax),
+		}
+	}
+
+	//
+	// TAC温湿度
+	//
+	cooling := []int{}
+	for _, m := range []int{6, 7, 8, 9} {
+		cooling = append(cooling, monthIndex[m-1]...)
+	}
+	heating := []int{}
+	for _, m := range []int{12, 1, 2, 3} {
+		heating = append(heating, monthIndex[m-1]...)
+	}
+	for k, p := range []float64{2.5, 5.0} {
+		dc.TAC.CoolingDB[k] = percentile(msm.TMP, cooling, 100-p)
+		dc.TAC.CoolingMR[k] = percentile(msm.MR, cooling, 100-p)
+		dc.TAC.CoolingEnthalpy[k] = percentile(ENT, cooling, 100-p)
+		dc.TAC.HeatingDB[k] = percentile(msm.TMP, heating, p)
+		dc.TAC.HeatingMR[k] = percentile(msm.MR, heating, p)
+	}
+
+	return dc
+}
+
+// 添字 index の時刻の月別の気温を集計します。
+func monthlyDesignCondition(msm *MsmTarget, index []int) MonthlyDesignCondition {
+	mdc := MonthlyDesignCondition{DBMax: math.Inf(-1), DBMin: math.Inf(1)}
+	if len(index) == 0 {
+		return MonthlyDesignCondition{DBMean: math.NaN(), DBRange: math.NaN(), DBMax: math.NaN(), DBMin: math.NaN()}
+	}
+
+	var rangeSum float64
+	var days int
+	msm.eachDay(index, func(_ time.Time, dayIndex []int) {
+		dayMax, dayMin := math.Inf(-1), math.Inf(1)
+		for _, i := range dayIndex {
+			TMP := msm.TMP[i]
+			mdc.DBMean += TMP
+			mdc.DBMax = math.Max(mdc.DBMax, TMP)
+			mdc.DBMin = math.Min(mdc.DBMin, TMP)
+			dayMax = math.Max(dayMax, TMP)
+			dayMin = math.Min(dayMin, TMP)
+		}
+		rangeSum += dayMax - dayMin
+		days++
+	})
+	mdc.DBMean /= float64(len(index))
+	mdc.DBRange = rangeSum / float64(days)
+
+	return mdc
+}
+
+// 添字 index の時刻の直散分離結果から ASHRAE の晴天モデルの光学的厚さ taub, taud を求めます。
+// 直達日射の大気外日射に対する比が大きい上位10%の日を晴天日とし、太陽高度10°以上の時刻の
+// 法線面直達日射量 Eb = E0 exp(-taub m^ab) と水平面天空日射量 Ed = E0 exp(-taud m^ad) の
+// 対数の二乗誤差が最小となる値を座標降下法により求めます。
+//
+// 参照)
+//
+//	ASHRAE Handbook Fundamentals (2009) 14章 Clear-Sky Solar Radiation
+func fitClearSkyTau(msm *MsmTarget, index []int) (float64, float64) {
+	sr := msm.sr()
+	if sr == nil || len(sr) != len(msm.date) || msm.IN0 == nil || len(index) == 0 {
+		return math.NaN(), math.NaN()
+	}
+
+	// 日別の直達日射の大気外日射に対する比
+	type day struct {
+		index []int
+		kb    float64
+	}
+	days := []day{}
+	msm.eachDay(index, func(_ time.Time, dayIndex []int) {
+		var DN_sum, IN0_sum float64
+		for _, i := range dayIndex {
+			if msm.h[i] > 0 {
+				DN_sum += sr[i].DN
+				IN0_sum += msm.IN0[i]
+			}
+		}
+		if IN0_sum > 0 {
+			days = append(days, day{index: dayIndex, kb: DN_sum / IN0_sum})
+		}
+	})
+	sort.SliceStable(days, func(a, b int) bool { return days[a].kb > days[b].kb })
+	n := int(math.Ceil(float64(len(days)) * 0.1))
+
+	// 晴天日の時刻別のエアマスと日射量の比の対数
+	var m, lb, ld []float64
+	for _, d := range days[:n] {
+		for _, i := range d.index {
+			if msm.h[i] < 10 || sr[i].DN <= 0 || sr[i].SH <= 0 || msm.IN0[i] <= 0 {
+				continue
+			}
+			m = append(m, relativeAirMass(msm.h[i]))
+			lb = append(lb, math.Log(sr[i].DN/msm.IN0[i]))
+			ld = append(ld, math.Log(sr[i].SH/msm.IN0[i]))
+		}
+	}
+	if len(m) < 3 {
+		return math.NaN(), math.NaN()
+	}
+
+	f := func(taub float64, taud float64) float64 {
+		ab := 1.219 - 0.043*taub - 0.151*taud - 0.204*taub*taud
+		ad := 0.202 + 0.852*taub - 0.007*taud - 0.357*taub*taud
+		var e float64
+		for k := range m {
+			eb := lb[k] + taub*math.Pow(m[k], ab)
+			ed := ld[k] + taud*math.Pow(m[k], ad)
+			e += eb*eb + ed*ed
+		}
+		return e
+	}
+
+	taub, taud := 0.4, 2.2
+	for iter := 0; iter < 20; iter++ {
+		taub = goldenSection(func(x float64) float64 { return f(x, taud) }, 0.1, 1.5)
+		taud = goldenSection(func(x float64) float64 { return f(taub, x) }, 0.5, 4.0)
+	}
+
+	return math.Round(taub*1000) / 1000, math.Round(taud*1000) / 1000
+}
+
+// 区間 [a, b] で関数 f が最小となる値を黄金分割法により求めます。
+func goldenSection(f func(float64) float64, a float64, b float64) float64 {
+	r := (math.Sqrt(5) - 1) / 2
+	x1 := b - r*(b-a)
+	x2 := a + r*(b-a)
+	f1, f2 := f(x1), f(x2)
+	for k := 0; k < 50; k++ {
+		if f1 < f2 {
+			b, x2, f2 = x2, x1, f1
+			x1 = b - r*(b-a)
+			f1 = f(x1)
+		} else {
+			a, x1, f1 = x1, x2, f2
+			x2 = a + r*(b-a)
+			f2 = f(x2)
+		}
+	}
+	return (a + b) / 2
+}
+
+// 添字 index の時刻の値 list の p パーセンタイル値を線形補間により求めます。
+// p = 99.6 は超過確率0.4%の値を表します。欠測(NaN)は除きます。
+func percentile(list []float64, index []int, p float64) float64 {
+	values := make([]float64, 0, len(index))
+	for _, i := range index {
+		if !math.IsNaN(list[i]) {
+			values = append(values, list[i])
+		}
+	}
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sort.Float64s(values)
+
+	x := p / 100 * float64(len(values)-1)
+	j := int(math.Floor(x))
+	if j >= len(values)-1 {
+		return values[len(values)-1]
+	}
+	return values[j] + (values[j+1]-values[j])*(x-float64(j))
+}
+
+// 値 key が target に近い時刻の値 values の平均値(同時発生の平均値)を求めます。
+// key が target ±0.5 の範囲の時刻を対象とし、該当がない場合は範囲を広げます。
+func coincidentMean(key []float64, target float64, values []float64, index []int) float64 {
+	for width := 0.5; width <= 8.0; width *= 2 {
+		var sum float64
+		var n int
+		for _, i := range index {
+			if math.Abs(key[i]-target) <= width && !math.IsNaN(values[i]) {
+				sum += values[i]
+				n++
+			}
+		}
+		if n > 0 {
+			return sum / float64(n)
+		}
+	}
+	return math.NaN()
+}
+
+// 値 key が target に近い時刻の風向 W_dir [°] の最頻値を求めます。
+// 同数の場合は北から時計回りに最初の風向とします。
+func coincidentMode(key []float64, target float64, W_dir []float64, index []int) float64 {
+	for width := 0.5; width <= 8.0; width *= 2 {
+		var count [16]int
+		var n int
+		for _, i := range index {
+			if math.Abs(key[i]-target) <= width {
+				count[int(math.Round(W_dir[i]/22.5))%16]++
+				n++
+			}
+		}
+		if n > 0 {
+			k := 0
+			for j := 1; j < 16; j++ {
+				if count[j] > count[k] {
+					k = j
+				}
+			}
+			return float64(k) * 22.5
+		}
+	}
+	return math.NaN()
+}
+
+// 平均値と不偏標準偏差を求めます。要素数が2未満の場合、標準偏差は NaN とします。
+func meanAndStd(list []float64) (float64, float64) {
+	m := mean(list)
+	if len(list) < 2 {
+		return m, math.NaN()
+	}
+	var s float64
+	for _, v := range list {
+		s += (v - m) * (v - m)
+	}
+	return m, math.Sqrt(s / float64(len(list)-1))
+}
+
+// EPW形式の DESIGN CONDITIONS 行を出力します。
+// 書式は ASHRAE Handbook Fundamentals (2009) の Climatic Design Information に従います。
+func (dc *DesignConditions) ToEPWHeader(out *bytes.Buffer) {
+	heating, cooling, extremes := dc.headerFields()
+
+	fields := []string{"DESIGN CONDITIONS", "1", "Climate Design Data 2009 ASHRAE Handbook", ""}
+	fields = append(fields, heating...)
+	fields = append(fields, cooling...)
+	fields = append(fields, extremes...)
+
+	for k, field := range fields {
+		if k > 0 {
+			out.WriteString(",")
+		}
+		out.WriteString(field)
+	}
+	out.WriteString("\n")
+}
+
+// EPW形式の DESIGN CONDITIONS 行の暖房(15項目)、冷房(32項目)、極値(16項目)の各欄を
+// 見出しを先頭に付けて返します。
+func (dc *DesignConditions) headerFields() ([]string, []string, []string) {
+	f := func(v float64) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		return strconv.FormatFloat(v, 'f', 1, 64)
+	}
+	d := func(v float64) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		return strconv.Itoa(int(math.Round(v)))
+	}
+
+	// 暖房 (15項目)
+	h := dc.Heating
+	heating := []string{"Heating", strconv.Itoa(h.Month), f(h.DB996), f(h.DB990)}
+	for _, v := range h.Humidification {
+		heating = append(heating, f(v.DP), f(v.HR), f(v.MCDB))
+	}
+	for _, v := range h.ColdestMonthWS {
+		heating = append(heating, f(v.WS), f(v.MCDB))
+	}
+	heating = append(heating, f(h.MCWS996), d(h.PCWD996))
+
+	// 冷房 (32項目)
+	c := dc.Cooling
+	cooling := []string{"Cooling", strconv.Itoa(c.Month), f(c.DBRange)}
+	for _, v := range c.DB {
+		cooling = append(cooling, f(v.DB), f(v.MCWB))
+	}
+	for _, v := range c.WB {
+		cooling = append(cooling, f(v.WB), f(v.MCDB))
+	}
+	cooling = append(cooling, f(c.MCWS04), d(c.PCWD04))
+	for _, v := range c.Dehumidification {
+		cooling = append(cooling, f(v.DP), f(v.HR), f(v.MCDB))
+	}
+	for _, v := range c.Enthalpy {
+		cooling = append(cooling, f(v.Enthalpy), f(v.MCDB))
+	}
+	cooling = append(cooling, d(c.Hours8to4))
+
+	// 極値 (16項目)
+	e := dc.Extremes
+	extremes := []string{"Extremes", f(e.WS[0]), f(e.WS[1]), f(e.WS[2]), f(e.MaxWB),
+		f(e.MeanMinDB), f(e.MeanMaxDB), f(float64(e.StdMinDB)), f(float64(e.StdMaxDB))}
+	for _, r := range e.Return {
+		extremes = append(extremes, f(float64(r.MinDB)), f(float64(r.MaxDB)))
+	}
+
+	return heating, cooling, extremes
+}
+
+// 設計用外気条件をJSON形式で出力します。
+func (dc *DesignConditions) ToJSON(out *bytes.Buffer) {
+	b, err := json.MarshalIndent(dc, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	out.Write(b)
+	out.WriteString("\n")
+}
+
+// 設計用外気条件を項目名と値の組のCSV形式で出力します。
+func (dc *DesignConditions) ToCSV(out *bytes.Buffer) {
+	out.WriteString("item,value\n")
+	w := func(name string, v float64) {
+		out.WriteString(name)
+		out.WriteString(",")
+		if !math.IsNaN(v) {
+			out.WriteString(strconv.FormatFloat(v, 'f', 2, 64))
+		}
+		out.WriteString("\n")
+	}
+	pct := []string{"0.4", "1", "2"}
+
+	w("years", float64(dc.Years))
+	w("pressure", dc.Pressure)
+
+	h := dc.Heating
+	w("heating_month", float64(h.Month))
+	w("heating_db_99.6", h.DB996)
+	w("heating_db_99", h.DB990)
+	for k, p := range []string{"99.6", "99"} {
+		w("humidification_dp_"+p, h.Humidification[k].DP)
+		w("humidification_hr_"+p, h.Humidification[k].HR)
+		w("humidification_mcdb_"+p, h.Humidification[k].MCDB)
+	}
+	for k, p := range []string{"0.4", "1"} {
+		w("coldest_month_ws_"+p, h.ColdestMonthWS[k].WS)
+		w("coldest_month_mcdb_"+p, h.ColdestMonthWS[k].MCDB)
+	}
+	w("heating_mcws_99.6", h.MCWS996)
+	w("heating_pcwd_99.6", h.PCWD996)
+
+	c := dc.Cooling
+	w("cooling_month", float64(c.Month))
+	w("cooling_db_range", c.DBRange)
+	for k, p := range pct {
+		w("cooling_db_"+p, c.DB[k].DB)
+		w("cooling_mcwb_"+p, c.DB[k].MCWB)
+	}
+	for k, p := range pct {
+		w("evaporation_wb_"+p, c.WB[k].WB)
+		w("evaporation_mcdb_"+p, c.WB[k].MCDB)
+	}
+	w("cooling_mcws_0.4", c.MCWS04)
+	w("cooling_pcwd_0.4", c.PCWD04)
+	for k, p := range pct {
+		w("dehumidification_dp_"+p, c.Dehumidification[k].DP)
+		w("dehumidification_hr_"+p, c.Dehumidification[k].HR)
+		w("dehumidification_mcdb_"+p, c.Dehumidification[k].MCDB)
+	}
+	for k, p := range pct {
+		w("enthalpy_"+p, c.Enthalpy[k].Enthalpy)
+		w("enthalpy_mcdb_"+p, c.Enthalpy[k].MCDB)
+	}
+	w("hours_8_to_4_12.8_20.6", c.Hours8to4)
+
+	e := dc.Extremes
+	for k, p := range []string{"1", "2.5", "5"} {
+		w("extreme_ws_"+p, e.WS[k])
+	}
+	w("extreme_max_wb", e.MaxWB)
+	w("extreme_mean_min_db", e.MeanMinDB)
+	w("extreme_mean_max_db", e.MeanMaxDB)
+	w("extreme_std_min_db", float64(e.StdMinDB))
+	w("extreme_std_max_db", float64(e.StdMaxDB))
+	for _, r := range e.Return {
+		w(fmt.Sprintf("return_%d_min_db", r.N), float64(r.MinDB))
+		w(fmt.Sprintf("return_%d_max_db", r.N), float64(r.MaxDB))
+	}
+
+	for _, m := range dc.Monthly {
+		w(fmt.Sprintf("month_%d_db_mean", m.Month), m.DBMean)
+		w(fmt.Sprintf("month_%d_db_range", m.Month), m.DBRange)
+		w(fmt.Sprintf("month_%d_db_max", m.Month), m.DBMax)
+		w(fmt.Sprintf("month_%d_db_min", m.Month), m.DBMin)
+	}
+
+	for _, t := range dc.Tau {
+		w(fmt.Sprintf("month_%d_taub", t.Month), float64(t.Taub))
+		w(fmt.Sprintf("month_%d_taud", t.Month), float64(t.Taud))
+	}
+
+	for k, p := range []string{"2.5", "5"} {
+		w("tac_cooling_db_"+p, dc.TAC.CoolingDB[k])
+		w("tac_cooling_mr_"+p, dc.TAC.CoolingMR[k])
+		w("tac_cooling_enthalpy_"+p, dc.TAC.CoolingEnthalpy[k])
+		w("tac_heating_db_"+p, dc.TAC.HeatingDB[k])
+		w("tac_heating_mr_"+p, dc.TAC.HeatingMR[k])
+	}
+}
@@ -0,0 +1,115 @@
+package arcclimate
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// パーセンタイル値
+func Test_percentile(t *testing.T) {
+	list := []float64{5, 1, 4, 2, 3, math.NaN()}
+	index := []int{0, 1, 2, 3, 4, 5}
+
+	assert.Equal(t, 1.0, percentile(list, index, 0))
+	assert.Equal(t, 3.0, percentile(list, index, 50))
+	assert.Equal(t, 5.0, percentile(list, index, 100))
+	assert.InDelta(t, 4.6, percentile(list, index, 90), 1.0e-9)
+	assert.Equal(t, 2.0, percentile(list, []int{1, 3}, 100))
+}
+
+// 同時発生の平均値と最頻風向
+func Test_coincident(t *testing.T) {
+	key := []float64{30.0, 30.2, 25.0, 29.8}
+	values := []float64{20.0, 22.0, 10.0, 24.0}
+	W_dir := []float64{180.0, 180.0, 0.0, 360.0}
+	index := []int{0, 1, 2, 3}
+
+	assert.InDelta(t, 22.0, coincidentMean(key, 30.0, values, index), 1.0e-9)
+	assert.Equal(t, 180.0, coincidentMode(key, 30.0, W_dir, index))
+
+	// 該当する時刻がない場合は範囲を広げる
+	assert.InDelta(t, 10.0, coincidentMean(key, 24.0, values, index), 1.0e-9)
+}
+
+// 開始年 start_year から終了年 end_year までの試験用の気象データ
+func designTestTarget(start_year int, end_year int) *MsmTarget {
+	msm := &MsmTarget{}
+	for y := start_year; y <= end_year; y++ {
+		start := time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC)
+		for d := 0; d < 365; d++ {
+			for h := 0; h < 24; h++ {
+				date := start.Add(time.Duration(d*24+h) * time.Hour)
+				// 年較差20℃・日較差8℃の正弦波 (1月中旬が最寒, 7月中旬が最暖)
+				TMP := 15 - 10*math.Cos(2*math.Pi*float64(d-15)/365) - 4*math.Cos(2*math.Pi*float64(h-2)/24) + float64(y-2011)
+				msm.date = append(msm.date, date)
+				msm.TMP = append(msm.TMP, TMP)
+				msm.MR = append(msm.MR, 8.0)
+				msm.PRES = append(msm.PRES, 101325.0)
+				msm.Pw = append(msm.Pw, 101325.0/100*8.0/(622.0+8.0))
+				msm.W_spd = append(msm.W_spd, 2.0+float64(h%3))
+				msm.W_dir = append(msm.W_dir, 22.5*float64(h%16))
+			}
+		}
+	}
+	return msm
+}
+
+// 設計用外気条件の計算とEPWのヘッダー
+func Test_CalcDesignConditions(t *testing.T) {
+	dc := designTestTarget(2011, 2012).CalcDesignConditions()
+	assert.Equal(t, 2, dc.Years)
+	assert.Equal(t, 1, dc.Heating.Month)
+	assert.Equal(t, 7, dc.Cooling.Month)
+	assert.True(t, dc.Heating.DB996 < dc.Heating.DB990)
+	assert.True(t, dc.Cooling.DB[0].DB > dc.Cooling.DB[1].DB && dc.Cooling.DB[1].DB > dc.Cooling.DB[2].DB)
+	assert.InDelta(t, 8.0, dc.Cooling.DBRange, 0.2)
+	assert.InDelta(t, 8.0, dc.Heating.Humidification[0].HR, 1.0e-6)
+	assert.InDelta(t, 0.5, dc.Extremes.MeanMaxDB-math.Floor(dc.Extremes.MeanMaxDB), 0.1)
+	assert.True(t, float64(dc.Extremes.Return[3].MaxDB) > float64(dc.Extremes.Return[0].MaxDB))
+
+	// 暖房15項目, 冷房32項目, 極値16項目
+	buf := bytes.NewBuffer(nil)
+	dc.ToEPWHeader(buf)
+	fields := strings.Split(strings.TrimSpace(buf.String()), ",")
+	assert.Equal(t, 4+1+15+1+32+1+16, len(fields))
+	assert.Equal(t, "Heating", fields[4])
+	assert.Equal(t, "Cooling", fields[20])
+	assert.Equal(t, "Extremes", fields[53])
+
+	// 1年分の場合は標準偏差を計算できない
+	dc = designTestTarget(2011, 2011).CalcDesignConditions()
+	assert.True(t, math.IsNaN(float64(dc.Extremes.StdMaxDB)))
+	buf.Reset()
+	dc.ToJSON(buf)
+	assert.Contains(t, buf.String(), `"std_max_db": null`)
+}
+
+// 同じ入力に対して同じ出力
+func Test_CalcDesignConditions_Reproducible(t *testing.T) {
+	// 年ごとに不規則にずらした気温 (合計の順序により丸め誤差が変わる値)
+	msm := designTestTarget(2011, 2011)
+	n := len(msm.date)
+	for y := 2012; y <= 2022; y++ {
+		for i := 0; i < n; i++ {
+			msm.date = append(msm.date, msm.date[i].AddDate(y-2011, 0, 0))
+			msm.TMP = append(msm.TMP, msm.TMP[i]+math.Sqrt(float64(y))/7)
+			msm.MR = append(msm.MR, msm.MR[i])
+			msm.PRES = append(msm.PRES, msm.PRES[i])
+			msm.Pw = append(msm.Pw, msm.Pw[i])
+			msm.W_spd = append(msm.W_spd, msm.W_spd[i])
+			msm.W_dir = append(msm.W_dir, msm.W_dir[i])
+		}
+	}
+
+	dc := msm.CalcDesignConditions()
+	for k := 0; k < 5; k++ {
+		e := msm.CalcDesignConditions().Extremes
+		assert.Equal(t, dc.Extremes.MeanMinDB, e.MeanMinDB)
+		assert.Equal(t, dc.Extremes.StdMaxDB, e.StdMaxDB)
+	}
+}
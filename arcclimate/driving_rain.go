@@ -0,0 +1,305 @@
+package arcclimate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//--------------------------------------
+// 外壁面の風雨量 (ISO 15927-3)
+//--------------------------------------
+
+// 風雨の連続期間(spell)を区切る無降雨時間 [h]
+// この時間を超えて外壁面の風雨量が0の場合に別の連続期間とします。
+const DrivingRainSpellGap = 96
+
+// 連続期間指数の再現期間 [年]
+const DrivingRainReturnPeriod = 3.0
+
+// 既定の壁係数 W (ISO 15927-3 Table 4)
+const DefaultWallFactor = 0.4
+
+// 地表面粗度区分 (ISO 15927-3 Table 2)
+type TerrainRoughness struct {
+	Category string  //区分
+	KR       float64 //地表面係数 K_R [-]
+	Z0       float64 //粗度長 z0 [m]
+	Zmin     float64 //最小高さ z_min [m]
+}
+
+// 地表面粗度区分の一覧
+// I:海岸・湖岸, II:農地, III:郊外・森林, IV:市街地
+var TerrainRoughnesses = []TerrainRoughness{
+	{"I", 0.17, 0.01, 2},
+	{"II", 0.19, 0.05, 4},
+	{"III", 0.22, 0.3, 8},
+	{"IV", 0.24, 1.0, 16},
+}
+
+// 地表面粗度区分 category, 高さ z [m] の粗度係数 C_R(z) = K_R ln(z/z0) を返します。
+// z が最小高さ z_min 未満の場合は C_R(z_min) とします。
+func RoughnessCoefficient(category string, z float64) (float64, error) {
+	for _, t := range TerrainRoughnesses {
+		if t.Category == category {
+			return t.KR * math.Log(math.Max(z, t.Zmin)/t.Z0), nil
+		}
+	}
+	return math.NaN(), fmt.Errorf("unknown terrain category %q", category)
+}
+
+// 風雨の連続期間
+type DrivingRainSpell struct {
+	Start time.Time `json:"start"` //開始時刻
+	End   time.Time `json:"end"`   //終了時刻
+	Total float64   `json:"total"` //連続期間の風雨量 [L/m2]
+}
+
+// 方位別の風雨量指数
+type DrivingRainOrientation struct {
+	Direction       string           `json:"direction"`         //壁面の向き(16方位)
+	Angle           float64          `json:"angle"`             //壁面の法線の方位角 [°] (北=0, 時計回り)
+	Annual          float64          `json:"annual"`            //年間風雨量指数 I_A [L/m2]
+	Spell           JSONFloat        `json:"spell"`             //連続期間指数 I_AS [L/m2] (再現期間3年)
+	AnnualMaxSpells []float64        `json:"annual_max_spells"` //年ごとの連続期間の風雨量の最大値 [L/m2]
+	MaxSpell        DrivingRainSpell `json:"max_spell"`         //風雨量が最大の連続期間
+}
+
+// 風雨量指数 (ISO 15927-3)
+type DrivingRainIndex struct {
+	Years        []int                    `json:"years"`        //対象年
+	Rain         float64                  `json:"rain"`         //年間降水量 [mm]
+	Orientations []DrivingRainOrientation `json:"orientations"` //方位別の風雨量指数
+}
+
+// 壁面の法線の方位角 angle [°] に対する時刻別の風雨量 [L/m2] (ISO 15927-3 式(1)) を計算します。
+// 風雨量は (2/9) v r^(8/9) cos(D-θ) で、壁面に向かう風の場合のみ計上します。
+// 風速と風向の積 v cos(D-θ) は、16方位の丸めの影響を受けないようにベクトル風速 UGRD, VGRD から直接求めます。
+func (msm *MsmTarget) airfieldDrivingRain(angle float64) []float64 {
+	theta := angle * math.Pi / 180
+	sin, cos := math.Sin(theta), math.Cos(theta)
+
+	q := make([]float64, len(msm.date))
+	for i := 0; i < len(msm.date); i++ {
+		r := msm.APCP01[i]
+		if !(r > 0) {
+			continue
+		}
+		// 風向 D は風が吹いてくる方向のため、壁面に向かう成分はベクトルの逆向き
+		vn := -(msm.UGRD[i]*sin + msm.VGRD[i]*cos)
+		if vn > 0 {
+			q[i] = 2.0 / 9.0 * vn * math.Pow(r, 8.0/9.0)
+		}
+	}
+	return q
+}
+
+// 時刻別の風雨量 q から風雨の連続期間を抽出します。
+// 風雨量が0の時間が DrivingRainSpellGap を超えた場合に連続期間を区切ります。
+func drivingRainSpells(date []time.Time, q []float64) []DrivingRainSpell {
+	spells := []DrivingRainSpell{}
+	var cur *DrivingRainSpell
+	for i := 0; i < len(date); i++ {
+		if !(q[i] > 0) {
+			continue
+		}
+		if cur != nil && date[i].Sub(cur.End).Hours() > DrivingRainSpellGap+1 {
+			spells = append(spells, *cur)
+			cur = nil
+		}
+		if cur == nil {
+			cur = &DrivingRainSpell{Start: date[i]}
+		}
+		cur.End = date[i]
+		cur.Total += q[i]
+	}
+	if cur != nil {
+		spells = append(spells, *cur)
+	}
+	return spells
+}
+
+// 年最大値 v にグンベル分布を積率法で当てはめ、再現期間 T [年] の値を返します。
+// 要素数が2未満の場合は NaN を返します。
+func gumbelReturnValue(v []float64, T float64) float64 {
+	m, s := meanAndStd(v)
+	if math.IsNaN(s) {
+		return math.NaN()
+	}
+	beta := s * math.Sqrt(6) / math.Pi
+	mu := m - 0.5772*beta
+	return mu - beta*math.Log(-math.Log(1-1/T))
+}
+
+// 方位別の年間風雨量指数と連続期間指数を計算します。(ISO 15927-3)
+// 連続期間はその開始時刻の年に計上し、連続期間指数は年最大値から再現期間3年の値として求めます。
+func (msm *MsmTarget) CalcDrivingRainIndex() *DrivingRainIndex {
+	years := msm.years()
+	nYears := float64(len(years))
+
+	var rain float64
+	for _, r := range msm.APCP01 {
+		rain += r
+	}
+
+	dr := &DrivingRainIndex{
+		Years:        years,
+		Rain:         rain / nYears,
+		Orientations: make([]DrivingRainOrientation, len(windDirectionNames)),
+	}
+
+	for k, name := range windDirectionNames {
+		angle := float64(k) * 22.5
+		q := msm.airfieldDrivingRain(angle)
+
+		var total float64
+		for _, v := range q {
+			total += v
+		}
+
+		// 年ごとの連続期間の最大値
+		maxSpells := make([]float64, len(years))
+		var maxSpell DrivingRainSpell
+		for _, s := range drivingRainSpells(msm.date, q) {
+			for j, y := range years {
+				if s.Start.Year() == y && s.Total > maxSpells[j] {
+					maxSpells[j] = s.Total
+				}
+			}
+			if s.Total > maxSpell.Total {
+				maxSpell = s
+			}
+		}
+
+		dr.Orientations[k] = DrivingRainOrientation{
+			Direction:       name,
+			Angle:           angle,
+			Annual:          total / nYears,
+			Spell:           JSONFloat(gumbelReturnValue(maxSpells, DrivingRainReturnPeriod)),
+			AnnualMaxSpells: maxSpells,
+			MaxSpell:        maxSpell,
+		}
+	}
+
+	return dr
+}
+
+// 風雨量指数をCSV形式で出力します。
+// 列は 壁面の向き, 方位角, 年間風雨量指数, 連続期間指数, 最大の連続期間の風雨量・開始時刻・終了時刻 です。
+func (dr *DrivingRainIndex) ToCSV(buf *bytes.Buffer) {
+	f := func(v float64) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		return strconv.FormatFloat(v, 'f', 1, 64)
+	}
+
+	buf.WriteString("direction,angle,annual,spell,max_spell,max_spell_start,max_spell_end\n")
+	for _, o := range dr.Orientations {
+		start, end := "", ""
+		if o.MaxSpell.Total > 0 {
+			start = o.MaxSpell.Start.Format("2006-01-02 15:04:05")
+			end = o.MaxSpell.End.Format("2006-01-02 15:04:05")
+		}
+		buf.WriteString(strings.Join([]string{
+			o.Direction,
+			strconv.FormatFloat(o.Angle, 'f', -1, 64),
+			f(o.Annual),
+			f(float64(o.Spell)),
+			f(o.MaxSpell.Total),
+			start,
+			end,
+		}, ","))
+		buf.WriteString("\n")
+	}
+}
+
+// 風雨量指数をJSON形式で出力します。
+func (dr *DrivingRainIndex) ToJSON(buf *bytes.Buffer) {
+	b, err := json.MarshalIndent(dr, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	buf.Write(b)
+	buf.WriteString("\n")
+}
+
+// 外壁面の風雨量の計算条件
+type WallRainConfig struct {
+	Orientation float64 //壁面の法線の方位角 [°] (北=0, 時計回り)
+	Height      float64 //壁面の高さ [m]
+	Terrain     string  //地表面粗度区分 (I, II, III, IV)
+	Topography  float64 //地形係数 C_T [-]
+	Obstruction float64 //遮蔽係数 O [-]
+	WallFactor  float64 //壁係数 W [-]
+}
+
+// 外壁面の風雨量
+type WallRain struct {
+	Config    WallRainConfig
+	Roughness float64   //粗度係数 C_R [-]
+	Factor    float64   //風雨量指数に乗じる係数 C_R C_T O W [-]
+	Annual    float64   //年間の外壁面の風雨量 I_WA [L/m2]
+	Spell     JSONFloat //外壁面の連続期間指数 [L/m2]
+	date      []time.Time
+	APCP01    []float64 //時刻別の降水量 [mm]
+	Airfield  []float64 //時刻別の風雨量 [L/m2]
+	Wall      []float64 //時刻別の外壁面の風雨量 [L/m2]
+}
+
+// 条件 cfg の外壁面の時刻別の風雨量 I_WA = I_A C_R C_T O W を計算します。(ISO 15927-3 式(3))
+func (msm *MsmTarget) CalcWallRain(cfg WallRainConfig) (*WallRain, error) {
+	CR, err := RoughnessCoefficient(cfg.Terrain, cfg.Height)
+	if err != nil {
+		return nil, err
+	}
+	factor := CR * cfg.Topography * cfg.Obstruction * cfg.WallFactor
+
+	wr := &WallRain{
+		Config:    cfg,
+		Roughness: CR,
+		Factor:    factor,
+		date:      msm.date,
+		APCP01:    msm.APCP01,
+		Airfield:  msm.airfieldDrivingRain(cfg.Orientation),
+		Wall:      make([]float64, len(msm.date)),
+	}
+
+	var total float64
+	for i, q := range wr.Airfield {
+		wr.Wall[i] = q * factor
+		total += wr.Wall[i]
+	}
+	years := msm.years()
+	wr.Annual = total / float64(len(years))
+
+	maxSpells := make([]float64, len(years))
+	for _, s := range drivingRainSpells(msm.date, wr.Wall) {
+		for j, y := range years {
+			if s.Start.Year() == y && s.Total > maxSpells[j] {
+				maxSpells[j] = s.Total
+			}
+		}
+	}
+	wr.Spell = JSONFloat(gumbelReturnValue(maxSpells, DrivingRainReturnPeriod))
+
+	return wr, nil
+}
+
+// 外壁面の時刻別の風雨量をCSV形式で出力します。
+// 列は 日時, 降水量, 風雨量, 外壁面の風雨量 です。
+func (wr *WallRain) ToCSV(buf *bytes.Buffer) {
+	buf.WriteString("date,APCP01,airfield,wall\n")
+	for i := range wr.date {
+		buf.WriteString(wr.date[i].Format("2006-01-02 15:04:05"))
+		for _, v := range []float64{wr.APCP01[i], wr.Airfield[i], wr.Wall[i]} {
+			buf.WriteString(",")
+			buf.WriteString(strconv.FormatFloat(v, 'f', 3, 64))
+		}
+		buf.WriteString("\n")
+	}
+}
@@ -0,0 +1,132 @@
+package arcclimate
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 南風 4 m/s, 指定した日の0時から降水量 1 mm/h の試験用データ
+// rain は 年 => 日(通日) => 降水の時間数
+func drivingRainTestTarget(rain map[int]map[int]int) *MsmTarget {
+	msm := &MsmTarget{}
+	for _, y := range []int{2011, 2012} {
+		start := time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC)
+		for d := 0; d < 365; d++ {
+			for h := 0; h < 24; h++ {
+				msm.date = append(msm.date, start.Add(time.Duration(d*24+h)*time.Hour))
+				msm.UGRD = append(msm.UGRD, 0.0)
+				msm.VGRD = append(msm.VGRD, 4.0)
+				r := 0.0
+				if h < rain[y][d] {
+					r = 1.0
+				}
+				msm.APCP01 = append(msm.APCP01, r)
+			}
+		}
+	}
+	return msm
+}
+
+// 粗度係数
+func Test_RoughnessCoefficient(t *testing.T) {
+	CR, err := RoughnessCoefficient("III", 10)
+	assert.Nil(t, err)
+	assert.InDelta(t, 0.22*math.Log(10/0.3), CR, 1e-9)
+
+	// 最小高さ未満
+	CR, _ = RoughnessCoefficient("IV", 5)
+	assert.InDelta(t, 0.24*math.Log(16), CR, 1e-9)
+
+	_, err = RoughnessCoefficient("V", 10)
+	assert.NotNil(t, err)
+}
+
+// 年最大値からの再現期間の値
+func Test_gumbelReturnValue(t *testing.T) {
+	v := gumbelReturnValue([]float64{10, 20}, 3)
+	m, s := meanAndStd([]float64{10, 20})
+	assert.InDelta(t, m+0.2538*s, v, 0.01)
+	assert.True(t, math.IsNaN(gumbelReturnValue([]float64{10}, 3)))
+}
+
+// 風雨量指数と連続期間
+func Test_CalcDrivingRainIndex(t *testing.T) {
+	// 2011年: 1日目と4日目(72時間以内)は同じ連続期間, 11日目は別の連続期間
+	// 2012年: 1日目に10時間
+	msm := drivingRainTestTarget(map[int]map[int]int{
+		2011: {0: 5, 3: 5, 10: 8},
+		2012: {0: 10},
+	})
+	q := 2.0 / 9.0 * 4.0
+
+	dr := msm.CalcDrivingRainIndex()
+	assert.Equal(t, []int{2011, 2012}, dr.Years)
+	assert.InDelta(t, 14.0, dr.Rain, 1e-9)
+
+	// 南向きの壁面のみ正面から風雨を受ける
+	south := dr.Orientations[8]
+	assert.Equal(t, "South", south.Direction)
+	assert.InDelta(t, 28*q/2, south.Annual, 1e-9)
+	assert.Equal(t, 0.0, dr.Orientations[0].Annual)
+	assert.InDelta(t, south.Annual*math.Cos(math.Pi/4), dr.Orientations[6].Annual, 1e-9)
+
+	// 年ごとの最大の連続期間はいずれも10時間分
+	assert.InDelta(t, 10*q, south.AnnualMaxSpells[0], 1e-9)
+	assert.InDelta(t, 10*q, south.AnnualMaxSpells[1], 1e-9)
+	assert.InDelta(t, 10*q, float64(south.Spell), 1e-9)
+	assert.Equal(t, time.Date(2011, 1, 1, 0, 0, 0, 0, time.UTC), south.MaxSpell.Start)
+	assert.Equal(t, time.Date(2011, 1, 4, 4, 0, 0, 0, time.UTC), south.MaxSpell.End)
+
+	buf := bytes.NewBuffer(nil)
+	dr.ToCSV(buf)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, "direction,angle,annual,spell,max_spell,max_spell_start,max_spell_end", lines[0])
+	assert.Equal(t, "North,0,0.0,0.0,0.0,,", lines[1])
+	assert.Equal(t, "South,180,12.4,8.9,8.9,2011-01-01 00:00:00,2011-01-04 04:00:00", lines[9])
+
+	buf.Reset()
+	dr.ToJSON(buf)
+	assert.Contains(t, buf.String(), `"direction": "South"`)
+}
+
+// 連続期間の区切り
+func Test_drivingRainSpells(t *testing.T) {
+	start := time.Date(2011, 1, 1, 0, 0, 0, 0, time.UTC)
+	date := make([]time.Time, 200)
+	q := make([]float64, 200)
+	for i := range date {
+		date[i] = start.Add(time.Duration(i) * time.Hour)
+	}
+	// 96時間の無降雨は同じ連続期間, 97時間で区切る
+	q[0], q[97], q[195] = 1, 2, 3
+	spells := drivingRainSpells(date, q)
+	assert.Equal(t, 2, len(spells))
+	assert.Equal(t, 3.0, spells[0].Total)
+	assert.Equal(t, date[195], spells[1].Start)
+}
+
+// 外壁面の風雨量
+func Test_CalcWallRain(t *testing.T) {
+	msm := drivingRainTestTarget(map[int]map[int]int{2011: {0: 5}, 2012: {0: 5}})
+	wr, err := msm.CalcWallRain(WallRainConfig{Orientation: 180, Height: 10, Terrain: "II", Topography: 1, Obstruction: 0.7, WallFactor: DefaultWallFactor})
+	assert.Nil(t, err)
+	CR := 0.19 * math.Log(10/0.05)
+	assert.InDelta(t, CR*0.7*0.4, wr.Factor, 1e-9)
+	assert.InDelta(t, 5*2.0/9.0*4.0*wr.Factor, wr.Annual, 1e-9)
+	assert.InDelta(t, wr.Annual, float64(wr.Spell), 1e-9)
+
+	buf := bytes.NewBuffer(nil)
+	wr.ToCSV(buf)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, "date,APCP01,airfield,wall", lines[0])
+	assert.Equal(t, len(msm.date)+1, len(lines))
+	assert.True(t, strings.HasPrefix(lines[1], "2011-01-01 00:00:00,1.000,0.889,"))
+
+	_, err = msm.CalcWallRain(WallRainConfig{Terrain: "X"})
+	assert.NotNil(t, err)
+}
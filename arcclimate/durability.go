@@ -0,0 +1,229 @@
+package arcclimate
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//--------------------------------------
+// 材料の耐久性に関する指標
+//--------------------------------------
+
+// 凍結融解の判定温度 [℃]
+// 気温が凍結の温度以下になった後、融解の温度以上になった時点で1回と数えます。
+const (
+	FreezeThawFreezeTemperature = -1.0
+	FreezeThawThawTemperature   = 1.0
+)
+
+// 湿潤な凍結融解とする凍結前の降水の期間 [h]
+const freezeThawWetHours = 72
+
+// ぬれ時間 (ISO 9223) の相対湿度 [%] と気温 [℃] の条件 (RH > 80%, TMP > 0℃)
+const (
+	wetnessRH  = 80.0
+	wetnessTMP = 0.0
+)
+
+// ぬれ時間の区分 τ1-τ5 (ISO 9223) の年間時間数 [h] の上限 (τ5 を除く)
+var TimeOfWetnessBIN = []float64{10, 250, 2500, 5500}
+
+// カビ指数 (VTT モデル) の材料の条件 (松の辺材, 窯乾燥)
+const (
+	mouldWoodSpecies = 0.0 //W: 松=0, トウヒ=1
+	mouldSurface     = 0.0 //SQ: 窯乾燥=0, 元の表面=1
+)
+
+// 顕微鏡で菌糸が確認できるカビ指数
+const mouldGrowthIndex = 1.0
+
+// 月別・年別の耐久性の指標の1行
+// Month が 0 の行は年間の値です。
+type DurabilityRow struct {
+	Year          int     `json:"year"`
+	Month         int     `json:"month"`           //月 (年間の場合は0)
+	FreezeThaw    int     `json:"freeze_thaw"`     //凍結融解の回数
+	WetFreezeThaw int     `json:"wet_freeze_thaw"` //凍結前72時間以内に降水があった凍結融解の回数
+	TOW           int     `json:"tow"`             //ぬれ時間 [h]
+	TOWCategory   string  `json:"tow_category"`    //ぬれ時間の区分 τ1-τ5 (年間の行のみ)
+	MouldMax      float64 `json:"mould_max"`       //カビ指数の最大値
+	MouldHours    int     `json:"mould_hours"`     //カビ指数が1以上の時間数 [h]
+}
+
+// 月別・年別の耐久性の指標
+type Durability struct {
+	Rows []DurabilityRow `json:"rows"` //年ごとに1月から12月, 年間の順
+}
+
+// 時刻別の耐久性の指標
+type durabilityHour struct {
+	freezeThaw    bool    //凍結融解の完了
+	wetFreezeThaw bool    //湿潤な凍結融解の完了
+	wet           bool    //ぬれ
+	mould         float64 //カビ指数
+}
+
+// カビの成長の限界相対湿度 [%] (Hukka and Viitanen 1999)
+func mouldCriticalRH(TMP float64) float64 {
+	if TMP <= 20 {
+		return -0.00267*TMP*TMP*TMP + 0.160*TMP*TMP - 3.13*TMP + 100
+	}
+	return 80
+}
+
+// カビ指数 M の1時間あたりの変化量を求めます。(Hukka and Viitanen 1999)
+// dry は成長に適さない条件が続いている時間 [h] です。
+func mouldGrowthRate(M float64, TMP float64, RH float64, dry float64) float64 {
+	RHcrit := mouldCriticalRH(TMP)
+	if TMP > 0 && RH >= RHcrit {
+		// 成長
+		k1 := 1.0
+		if M >= 1 {
+			k1 = 2.0
+		}
+		r := (RHcrit - RH) / (RHcrit - 100)
+		Mmax := 1 + 7*r - 2*r*r
+		k2 := math.Max(1-math.Exp(2.3*(M-Mmax)), 0)
+		days := 7 * math.Exp(-0.68*math.Log(TMP)-13.9*math.Log(RH)+0.14*mouldWoodSpecies-0.33*mouldSurface+66.02)
+		return k1 * k2 / days / 24
+	}
+
+	// 減衰 (1時間あたり。1日あたり -0.032, -0.016 に相当)
+	if dry <= 6 {
+		return -0.00133
+	} else if dry <= 24 {
+		return 0
+	}
+	return -0.000667
+}
+
+// 気温 TMP, 相対湿度 RH, 降水量 APCP01 から時刻別の凍結融解・ぬれ・カビ指数を求めます。
+func (msm *MsmTarget) durabilityHours() []durabilityHour {
+	hours := make([]durabilityHour, len(msm.date))
+
+	frozen := false
+	freezeWet := false
+	lastRain := math.Inf(1) // 最後の降水からの経過時間 [h]
+	M := 0.0
+	dry := 0.0
+	for i := 0; i < len(msm.date); i++ {
+		TMP := msm.TMP[i]
+		RH := math.Min(msm.RH[i], 100)
+
+		if i > 0 {
+			lastRain += msm.date[i].Sub(msm.date[i-1]).Hours()
+		}
+		if msm.APCP01[i] > 0 {
+			lastRain = 0
+		}
+
+		// 凍結融解
+		if !frozen && TMP <= FreezeThawFreezeTemperature {
+			frozen = true
+			freezeWet = lastRain <= freezeThawWetHours
+		} else if frozen && TMP >= FreezeThawThawTemperature {
+			frozen = false
+			hours[i].freezeThaw = true
+			hours[i].wetFreezeThaw = freezeWet
+		}
+
+		// ぬれ時間
+		hours[i].wet = RH > wetnessRH && TMP > wetnessTMP
+
+		// カビ指数
+		if TMP > 0 && RH >= mouldCriticalRH(TMP) {
+			dry = 0
+		} else {
+			dry++
+		}
+		M = math.Max(M+mouldGrowthRate(M, TMP, RH, dry), 0)
+		hours[i].mould = M
+	}
+
+	return hours
+}
+
+// 気温 TMP, 相対湿度 RH, 降水量 APCP01 から年ごとに月別と年間の材料の耐久性の指標を求めます。
+// 凍結融解は融解が完了した時刻の月に計上し、凍結前72時間以内に降水があった場合を湿潤な凍結融解とします。
+// ぬれ時間は ISO 9223 の相対湿度80%超かつ気温0℃超の時間数とし、年間の行にはその区分を付します。
+// カビ指数は VTT モデル (Hukka and Viitanen 1999) の松の辺材の値で、期間の始めを0として連続して計算します。
+func (msm *MsmTarget) CalcDurability() *Durability {
+	hours := msm.durabilityHours()
+	d := &Durability{Rows: []DurabilityRow{}}
+
+	msm.eachMonthAndYear(func(index []int, month int) {
+		row := msm.durabilityRow(hours, index, month)
+		if month == 0 {
+			row.TOWCategory = timeOfWetnessCategory(row.TOW)
+		}
+		d.Rows = append(d.Rows, row)
+	})
+
+	return d
+}
+
+// 年間のぬれ時間 TOW [h] の区分 τ1-τ5 (ISO 9223) を返します。
+// 境界の時間数は下の区分に含めます。 ex) τ1: TOW ≤ 10, τ2: 10 < TOW ≤ 250
+func timeOfWetnessCategory(TOW int) string {
+	k := 0
+	for k < len(TimeOfWetnessBIN) && float64(TOW) > TimeOfWetnessBIN[k] {
+		k++
+	}
+	return "τ" + strconv.Itoa(k+1)
+}
+
+// 時刻 index の耐久性の指標を集計します。
+func (msm *MsmTarget) durabilityRow(hours []durabilityHour, index []int, month int) DurabilityRow {
+	row := DurabilityRow{
+		Year:  msm.date[index[0]].Year(),
+		Month: month,
+	}
+	for _, i := range index {
+		h := hours[i]
+		if h.freezeThaw {
+			row.FreezeThaw++
+		}
+		if h.wetFreezeThaw {
+			row.WetFreezeThaw++
+		}
+		if h.wet {
+			row.TOW++
+		}
+		row.MouldMax = math.Max(row.MouldMax, h.mould)
+		if h.mould >= mouldGrowthIndex {
+			row.MouldHours++
+		}
+	}
+	return row
+}
+
+// 耐久性の指標をCSV形式で出力します。年間の行の月は annual とします。
+func (d *Durability) ToCSV(buf *bytes.Buffer) {
+	buf.WriteString("year,month,freeze_thaw,wet_freeze_thaw,tow,tow_category,mould_max,mould_hours\n")
+	for _, row := range d.Rows {
+		buf.WriteString(strings.Join([]string{
+			strconv.Itoa(row.Year),
+			monthOrAnnual(row.Month),
+			strconv.Itoa(row.FreezeThaw),
+			strconv.Itoa(row.WetFreezeThaw),
+			strconv.Itoa(row.TOW),
+			row.TOWCategory,
+			strconv.FormatFloat(row.MouldMax, 'f', 2, 64),
+			strconv.Itoa(row.MouldHours),
+		}, ","))
+		buf.WriteString("\n")
+	}
+}
+
+// 耐久性の指標をJSON形式で出力します。
+func (d *Durability) ToJSON(buf *bytes.Buffer) {
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	buf.Write(b)
+	buf.WriteString("\n")
+}
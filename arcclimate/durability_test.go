@@ -0,0 +1,118 @@
+package arcclimate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// カビの成長の限界相対湿度と減衰
+func Test_mouldGrowthRate(t *testing.T) {
+	assert.InDelta(t, 80.0, mouldCriticalRH(20), 0.1)
+	assert.Equal(t, 80.0, mouldCriticalRH(25))
+	assert.True(t, mouldCriticalRH(5) > mouldCriticalRH(15))
+
+	assert.Equal(t, -0.00133, mouldGrowthRate(2, 20, 50, 3))
+	assert.Equal(t, 0.0, mouldGrowthRate(2, 20, 50, 12))
+	assert.Equal(t, -0.000667, mouldGrowthRate(2, 20, 50, 30))
+	assert.Equal(t, -0.000667, mouldGrowthRate(2, -5, 99, 30))
+
+	// 乾燥した状態が24時間を超えて続く場合の減衰は1日あたり -0.016
+	var dM float64
+	for dry := 25.0; dry <= 48; dry++ {
+		dM += mouldGrowthRate(2, 20, 50, dry)
+	}
+	assert.InDelta(t, -0.016, dM, 1e-4)
+	assert.True(t, mouldGrowthRate(0, 20, 97, 0) > 0)
+}
+
+// ぬれ時間の区分の境界は下の区分に含める
+func Test_timeOfWetnessCategory(t *testing.T) {
+	assert.Equal(t, "τ1", timeOfWetnessCategory(0))
+	assert.Equal(t, "τ1", timeOfWetnessCategory(10))
+	assert.Equal(t, "τ2", timeOfWetnessCategory(11))
+	assert.Equal(t, "τ2", timeOfWetnessCategory(250))
+	assert.Equal(t, "τ3", timeOfWetnessCategory(251))
+	assert.Equal(t, "τ3", timeOfWetnessCategory(2500))
+	assert.Equal(t, "τ4", timeOfWetnessCategory(5500))
+	assert.Equal(t, "τ5", timeOfWetnessCategory(5501))
+}
+
+// 凍結融解・ぬれ時間・カビ指数
+func Test_CalcDurability(t *testing.T) {
+	// 2011年12月31日から2日間
+	// 夜間(0-11時)は -3℃, 昼間(12-23時)は 3℃・相対湿度90%
+	// 1日目の0時に降水 (2回目の凍結も72時間以内)
+	start := time.Date(2011, 12, 31, 0, 0, 0, 0, time.UTC)
+	d := hourlyTestTarget(start, 48, func(i int) hourlyTestValue {
+		if i == 0 {
+			return hourlyTestValue{TMP: 2, RH: 90, APCP01: 1}
+		}
+		if i%24 < 12 {
+			return hourlyTestValue{TMP: -3, RH: 70, APCP01: 0}
+		}
+		return hourlyTestValue{TMP: 3, RH: 90, APCP01: 0}
+	}).CalcDurability()
+
+	assert.Equal(t, 4, len(d.Rows))
+	dec, y2011, jan := d.Rows[0], d.Rows[1], d.Rows[2]
+	assert.Equal(t, 12, dec.Month)
+	assert.Equal(t, 0, y2011.Month)
+	assert.Equal(t, 1, jan.Month)
+	assert.Equal(t, 1, dec.FreezeThaw)
+	assert.Equal(t, 1, dec.WetFreezeThaw)
+	assert.Equal(t, 1, jan.FreezeThaw)
+	assert.Equal(t, 1, jan.WetFreezeThaw)
+	assert.Equal(t, 13, dec.TOW)
+	assert.Equal(t, 12, jan.TOW)
+	assert.Equal(t, "τ2", y2011.TOWCategory)
+	assert.Equal(t, "", dec.TOWCategory)
+
+	// 降水がない場合は湿潤な凍結融解としない
+	dry := hourlyTestTarget(start, 48, func(i int) hourlyTestValue {
+		if i%24 < 12 {
+			return hourlyTestValue{TMP: -3, RH: 70, APCP01: 0}
+		}
+		return hourlyTestValue{TMP: 3, RH: 90, APCP01: 0}
+	}).CalcDurability()
+	assert.Equal(t, 1, dry.Rows[0].FreezeThaw)
+	assert.Equal(t, 0, dry.Rows[0].WetFreezeThaw)
+
+	buf := bytes.NewBuffer(nil)
+	d.ToCSV(buf)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, "year,month,freeze_thaw,wet_freeze_thaw,tow,tow_category,mould_max,mould_hours", lines[0])
+	assert.True(t, strings.HasPrefix(lines[2], "2011,annual,1,1,13,τ2,"))
+
+	buf.Reset()
+	d.ToJSON(buf)
+	assert.Contains(t, buf.String(), `"tow_category": "τ2"`)
+}
+
+// 湿潤な条件が続くとカビ指数が増加し、乾燥すると減少する
+func Test_CalcDurability_Mould(t *testing.T) {
+	start := time.Date(2011, 6, 1, 0, 0, 0, 0, time.UTC)
+	msm := hourlyTestTarget(start, 60*24, func(i int) hourlyTestValue {
+		if i < 30*24 {
+			return hourlyTestValue{TMP: 20, RH: 97, APCP01: 0}
+		}
+		return hourlyTestValue{TMP: 20, RH: 50, APCP01: 0}
+	})
+	hours := msm.durabilityHours()
+
+	// 約10日でカビ指数1
+	assert.InDelta(t, 1.0, hours[10*24].mould, 0.1)
+	assert.True(t, hours[30*24-1].mould > 2)
+	assert.True(t, hours[60*24-1].mould < hours[30*24-1].mould)
+
+	d := msm.CalcDurability()
+	assert.Equal(t, 3, len(d.Rows))
+	assert.Equal(t, hours[30*24-1].mould, d.Rows[0].MouldMax)
+	assert.True(t, d.Rows[0].MouldHours > 19*24)
+	// ぬれ時間 720時間
+	assert.Equal(t, 720, d.Rows[2].TOW)
+	assert.Equal(t, "τ3", d.Rows[2].TOWCategory)
+}
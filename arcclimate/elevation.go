@@ -74,6 +74,23 @@ func (mesh_elevation_master *ElevationMaster) Elevation3d(lat float64, lon float
 	return elevation
 }
 
+// 3次メッシュ（1㎞メッシュ）の平均標高データ mesh_elevation_master を用いて、緯度 lat, 経度 lonの地点の標高[m]の取得します。
+// 1次メッシュの標高データが未読込の場合は読み込みます。
+// 標高データが存在しない地点(海上など)は0mとします。
+func (mesh_elevation_master *ElevationMaster) Elevation3dOrZero(lat float64, lon float64) float64 {
+	meshcode1d, meshcode23d := MeshCodeFromLatLon(lat, lon)
+
+	if _, ok := mesh_elevation_master.DfMeshEle[meshcode1d]; !ok {
+		if fileExistsInData(fmt.Sprintf("data/mesh_3d_ele_%d.csv", meshcode1d)) {
+			mesh_elevation_master.Read3dMeshElevation(meshcode1d)
+		} else {
+			mesh_elevation_master.DfMeshEle[meshcode1d] = map[int]float64{}
+		}
+	}
+
+	return mesh_elevation_master.DfMeshEle[meshcode1d][meshcode23d]
+}
+
 func (msm_elevation_master *ElevationMaster) Elevation2d(codeSN int, codeWE int) float64 {
 	return msm_elevation_master.DfMsmEle[codeSN][codeWE]
 }
@@ -109,6 +126,12 @@ type ElevationApiResnponse struct {
 //go:embed data/*.csv
 var f embed.FS
 
+// 埋め込みデータにファイル path が存在するかを返します。
+func fileExistsInData(path string) bool {
+	_, err := f.Open(path)
+	return err == nil
+}
+
 // 経度 lon, 緯度 lat の補完に必要なマスタ読み取り
 func NewElevationMaster(lat float64, lon float64) *ElevationMaster {
 	ele := &ElevationMaster{
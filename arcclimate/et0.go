@@ -0,0 +1,162 @@
+package arcclimate
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+//--------------------------------------
+// 基準蒸発散量 (FAO-56 Penman-Monteith)
+//--------------------------------------
+
+// 基準作物(草地)のアルベド (FAO-56)
+const et0Albedo = 0.23
+
+// 風速の観測高さ [m] (MSMの地上10mの風)
+const et0WindHeight = 10.0
+
+// 日別の基準蒸発散量と水収支
+type ET0Day struct {
+	Date    time.Time //日付
+	ET0     float64   //基準蒸発散量 (単位:mm)
+	APCP01  float64   //降水量 (単位:mm)
+	Balance float64   //水収支 = 降水量 - 基準蒸発散量 (単位:mm)
+}
+
+// 月別の基準蒸発散量と水収支 (Month = 0 は年間)
+type ET0Month struct {
+	YearMonth
+	ET0     float64 //基準蒸発散量 (単位:mm)
+	APCP01  float64 //降水量 (単位:mm)
+	Balance float64 //水収支 = 降水量 - 基準蒸発散量 (単位:mm)
+}
+
+// 高さ z [m] の風速 uz [m/s] を地上2mの風速に換算します。(FAO-56 式(47))
+func windSpeed2m(uz float64, z float64) float64 {
+	return uz * 4.87 / math.Log(67.8*z-5.42)
+}
+
+// 時間単位の基準蒸発散量 ET0 [mm/h] を求めます。(FAO-56 式(53))
+//
+//	TMP(float64): 気温 (℃)
+//	Pw(float64): 水蒸気分圧 (hPa)
+//	PRES(float64): 気圧 (Pa)
+//	u2(float64): 地上2mの風速 (m/s)
+//	Rn(float64): 正味放射量 (MJ/m2・h)
+//	G(float64): 地中伝熱量 (MJ/m2・h)
+func hourlyET0(TMP float64, Pw float64, PRES float64, u2 float64, Rn float64, G float64) float64 {
+	es := 0.6108 * math.Exp(17.27*TMP/(TMP+237.3)) // 飽和水蒸気圧 [kPa]
+	ea := Pw / 10                                  // 水蒸気圧 [kPa]
+	delta := 4098 * es / ((TMP + 237.3) * (TMP + 237.3))
+	gamma := 0.665e-3 * PRES / 1000 // 乾湿計定数 [kPa/℃]
+
+	return (0.408*delta*(Rn-G) + gamma*37/(TMP+273)*u2*(es-ea)) / (delta + gamma*(1+0.34*u2))
+}
+
+// 時刻別の基準蒸発散量 ET0 [mm] を FAO-56 の Penman-Monteith 式により計算します。
+// 正味放射量は日射量(アルベド0.23)の吸収量から夜間放射量 NR を差し引いて求め、
+// 地中伝熱量は日射がある時間は正味放射量の0.1倍、ない時間は0.5倍とします。
+// 風速はベクトル風速 UGRD, VGRD の大きさを地上2mの値に換算して使用します。
+// 夜間の結露などによる負の値もそのまま出力します。
+func (msm *MsmTarget) CalcET0() {
+	DSWRF := msm.dswrf()
+
+	msm.ET0 = make([]float64, len(msm.date))
+
+	for i := 0; i < len(msm.date); i++ {
+		Rs := DSWRF[i]
+		Rn := (1-et0Albedo)*Rs - msm.NR[i]
+
+		G := 0.5 * Rn
+		if Rs > 0 {
+			G = 0.1 * Rn
+		}
+
+		u2 := windSpeed2m(math.Hypot(msm.UGRD[i], msm.VGRD[i]), et0WindHeight)
+
+		msm.ET0[i] = hourlyET0(msm.TMP[i], msm.Pw[i], msm.PRES[i], u2, Rn, G)
+	}
+}
+
+// 日別の基準蒸発散量と降水量の合計および水収支を求めます。
+// 基準蒸発散量は CalcET0 で計算済みである必要があります。
+func (msm *MsmTarget) DailyET0() []ET0Day {
+	days := []ET0Day{}
+
+	msm.eachDay(nil, func(date time.Time, index []int) {
+		var ET0, APCP01 float64
+		for _, i := range index {
+			ET0 += msm.ET0[i]
+			APCP01 += msm.APCP01[i]
+		}
+		days = append(days, ET0Day{
+			Date:    date,
+			ET0:     ET0,
+			APCP01:  APCP01,
+			Balance: APCP01 - ET0,
+		})
+	})
+
+	return days
+}
+
+// 日別の基準蒸発散量 days から月別と年間の合計および水収支を求めます。
+// 各年の12か月の後に年間(Month = 0)を追加します。
+func MonthlyET0(days []ET0Day) []ET0Month {
+	months := []ET0Month{}
+	var annual *ET0Month
+
+	for d, day := range days {
+		ym := YearMonth{day.Date.Year(), int(day.Date.Month())}
+		if len(months) == 0 || months[len(months)-1].YearMonth != ym {
+			months = append(months, ET0Month{YearMonth: ym})
+		}
+		m := &months[len(months)-1]
+		m.ET0 += day.ET0
+		m.APCP01 += day.APCP01
+		m.Balance += day.Balance
+
+		if annual == nil {
+			annual = &ET0Month{YearMonth: YearMonth{ym.Year, 0}}
+		}
+		annual.ET0 += day.ET0
+		annual.APCP01 += day.APCP01
+		annual.Balance += day.Balance
+
+		if d == len(days)-1 || days[d+1].Date.Year() != ym.Year {
+			months = append(months, *annual)
+			annual = nil
+		}
+	}
+
+	return months
+}
+
+// 日別の基準蒸発散量と水収支をCSV形式で出力します。
+func ET0DaysToCSV(buf *bytes.Buffer, days []ET0Day) {
+	buf.WriteString("date,ET0,APCP01,balance\n")
+	for _, day := range days {
+		buf.WriteString(day.Date.Format("2006-01-02"))
+		for _, v := range []float64{day.ET0, day.APCP01, day.Balance} {
+			buf.WriteString(",")
+			buf.WriteString(strconv.FormatFloat(v, 'f', 2, 64))
+		}
+		buf.WriteString("\n")
+	}
+}
+
+// 月別・年間の基準蒸発散量と水収支をCSV形式で出力します。年間の行の月は annual とします。
+func ET0MonthsToCSV(buf *bytes.Buffer, months []ET0Month) {
+	buf.WriteString("year,month,ET0,APCP01,balance\n")
+	for _, m := range months {
+		buf.WriteString(fmt.Sprintf("%d,%s", m.Year, monthOrAnnual(m.Month)))
+		for _, v := range []float64{m.ET0, m.APCP01, m.Balance} {
+			buf.WriteString(",")
+			buf.WriteString(strconv.FormatFloat(v, 'f', 1, 64))
+		}
+		buf.WriteString("\n")
+	}
+}
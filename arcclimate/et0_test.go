@@ -0,0 +1,77 @@
+package arcclimate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 地上2mの風速 (FAO-56 例題14)
+func Test_windSpeed2m(t *testing.T) {
+	assert.InDelta(t, 2.4, windSpeed2m(3.2, 10), 0.01)
+}
+
+// 時間単位の基準蒸発散量 (FAO-56 例題19)
+func Test_hourlyET0(t *testing.T) {
+	// 14-15時 (気温38℃, 水蒸気圧3.445kPa, 風速3.3m/s, 正味放射量1.749MJ/m2・h)
+	assert.InDelta(t, 0.63, hourlyET0(38, 34.45, 101200, 3.3, 1.749, 0.175), 0.01)
+	// 2-3時 (気温28℃, 風速1.9m/s, 正味放射量-0.100MJ/m2・h)
+	assert.InDelta(t, 0.0, hourlyET0(28, 34.45, 101200, 1.9, -0.100, -0.050), 0.01)
+}
+
+// 日別・月別の基準蒸発散量と水収支
+func Test_CalcET0(t *testing.T) {
+	msm := &MsmTarget{}
+	start := time.Date(2011, 12, 31, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 48; i++ {
+		msm.date = append(msm.date, start.Add(time.Duration(i)*time.Hour))
+		msm.TMP = append(msm.TMP, 20.0)
+		msm.Pw = append(msm.Pw, 11.7)
+		msm.PRES = append(msm.PRES, 101325.0)
+		msm.UGRD = append(msm.UGRD, 3.0)
+		msm.VGRD = append(msm.VGRD, 4.0)
+		msm.NR = append(msm.NR, 0.3)
+		msm.APCP01 = append(msm.APCP01, 0.5)
+		Rs := 0.0
+		if i%24 >= 6 && i%24 < 18 {
+			Rs = 1.5
+		}
+		msm.DSWRF_msm = append(msm.DSWRF_msm, Rs)
+	}
+	msm.CalcET0()
+
+	// 日中は夜間より大きい
+	assert.True(t, msm.ET0[12] > 0.2)
+	assert.True(t, msm.ET0[12] > msm.ET0[0])
+
+	days := msm.DailyET0()
+	assert.Equal(t, 2, len(days))
+	var sum float64
+	for _, v := range msm.ET0[:24] {
+		sum += v
+	}
+	assert.InDelta(t, sum, days[0].ET0, 1e-9)
+	assert.InDelta(t, 12.0, days[0].APCP01, 1e-9)
+	assert.InDelta(t, 12.0-sum, days[0].Balance, 1e-9)
+
+	// 年ごとに12月(1月)の行と年間の行
+	months := MonthlyET0(days)
+	assert.Equal(t, 4, len(months))
+	assert.Equal(t, YearMonth{2011, 12}, months[0].YearMonth)
+	assert.Equal(t, YearMonth{2011, 0}, months[1].YearMonth)
+	assert.Equal(t, months[0].ET0, months[1].ET0)
+	assert.Equal(t, YearMonth{2012, 1}, months[2].YearMonth)
+
+	buf := bytes.NewBuffer(nil)
+	ET0MonthsToCSV(buf, months)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, "year,month,ET0,APCP01,balance", lines[0])
+	assert.True(t, strings.HasPrefix(lines[2], "2011,annual,"))
+
+	buf.Reset()
+	ET0DaysToCSV(buf, days)
+	assert.True(t, strings.HasPrefix(buf.String(), "date,ET0,APCP01,balance\n2011-12-31,"))
+}
@@ -3,6 +3,7 @@ package arcclimate
 import (
 	"bytes"
 	"fmt"
+	"log"
 	"math"
 	"strconv"
 )
@@ -39,6 +40,56 @@ func (df_save *MsmTarget) ToCSV(buf *bytes.Buffer) {
 	}
 	buf.WriteString(",w_spd")
 	buf.WriteString(",w_dir")
+	if df_save.WindMode == WindModeContinuous {
+		buf.WriteString(",w_spd16")
+		buf.WriteString(",w_dir16")
+	}
+	if df_save.CS != nil {
+		buf.WriteString(",DSWRF_cs")
+		buf.WriteString(",DN_cs")
+		buf.WriteString(",SH_cs")
+		if df_save.DSWRF_est != nil {
+			buf.WriteString(",KC_est")
+		}
+		if df_save.DSWRF_msm != nil {
+			buf.WriteString(",KC_msm")
+		}
+	}
+	if df_save.CC != nil {
+		buf.WriteString(",CC")
+	}
+	if df_save.IL != nil {
+		buf.WriteString(",IL_GH")
+		buf.WriteString(",IL_DN")
+		buf.WriteString(",IL_SH")
+		buf.WriteString(",ZL")
+	}
+	if df_save.PS != nil {
+		buf.WriteString(",DT_exact")
+		buf.WriteString(",TWB")
+		buf.WriteString(",ENT")
+		buf.WriteString(",SV")
+		buf.WriteString(",RHO")
+	}
+	if df_save.TI != nil {
+		buf.WriteString(",WBGT")
+		buf.WriteString(",HI")
+		buf.WriteString(",DI")
+		buf.WriteString(",AT")
+	}
+	if df_save.ET0 != nil {
+		buf.WriteString(",ET0")
+	}
+	if df_save.WTR != nil {
+		buf.WriteString(",WTR")
+	}
+	if df_save.SN != nil {
+		buf.WriteString(",SNOW")
+		buf.WriteString(",SWE")
+		buf.WriteString(",SD")
+		buf.WriteString(",DSLS")
+		buf.WriteString(",ALB")
+	}
 	buf.WriteString("\n")
 
 	writeFloat := func(v float64) {
@@ -49,6 +100,13 @@ func (df_save *MsmTarget) ToCSV(buf *bytes.Buffer) {
 			buf.WriteString("0.0")
 		}
 	}
+	writeFloatOrEmpty := func(v float64) {
+		if !math.IsNaN(v) {
+			writeFloat(v)
+		} else {
+			buf.WriteString(",")
+		}
+	}
 	for i := 0; i < len(df_save.date); i++ {
 		buf.WriteString(df_save.date[i].Format("2006-01-02 15:04:05"))
 		writeFloat(df_save.TMP[i])
@@ -88,6 +146,56 @@ func (df_save *MsmTarget) ToCSV(buf *bytes.Buffer) {
 		}
 		writeFloat(df_save.W_spd[i])
 		writeFloat(df_save.W_dir[i])
+		if df_save.WindMode == WindModeContinuous {
+			writeFloat(df_save.W_spd16[i])
+			writeFloat(df_save.W_dir16[i])
+		}
+		if df_save.CS != nil {
+			writeFloat(df_save.CS[i].TH)
+			writeFloat(df_save.CS[i].DN)
+			writeFloat(df_save.CS[i].SH)
+			if df_save.DSWRF_est != nil {
+				writeFloatOrEmpty(clearSkyIndex(df_save.DSWRF_est[i], df_save.CS[i].TH))
+			}
+			if df_save.DSWRF_msm != nil {
+				writeFloatOrEmpty(clearSkyIndex(df_save.DSWRF_msm[i], df_save.CS[i].TH))
+			}
+		}
+		if df_save.CC != nil {
+			writeFloat(df_save.CC[i])
+		}
+		if df_save.IL != nil {
+			writeFloat(df_save.IL[i].GH)
+			writeFloat(df_save.IL[i].DN)
+			writeFloat(df_save.IL[i].SH)
+			writeFloat(df_save.IL[i].ZL)
+		}
+		if df_save.PS != nil {
+			writeFloatOrEmpty(df_save.PS[i].DT)
+			writeFloat(df_save.PS[i].TWB)
+			writeFloat(df_save.PS[i].ENT)
+			writeFloat(df_save.PS[i].SV)
+			writeFloat(df_save.PS[i].RHO)
+		}
+		if df_save.TI != nil {
+			writeFloatOrEmpty(df_save.TI[i].WBGT)
+			writeFloat(df_save.TI[i].HI)
+			writeFloat(df_save.TI[i].DI)
+			writeFloat(df_save.TI[i].AT)
+		}
+		if df_save.ET0 != nil {
+			writeFloat(df_save.ET0[i])
+		}
+		if df_save.WTR != nil {
+			writeFloat(df_save.WTR[i])
+		}
+		if df_save.SN != nil {
+			writeFloat(df_save.SN[i].SNOW)
+			writeFloat(df_save.SN[i].SWE)
+			writeFloat(df_save.SN[i].SD)
+			writeFloat(float64(df_save.SN[i].DSLS))
+			writeFloat(df_save.SN[i].ALB)
+		}
 		buf.WriteString("\n")
 	}
 }
@@ -99,6 +207,9 @@ func (df_save *MsmTarget) ToCSV(buf *bytes.Buffer) {
 //	法線面直達日射量、水平面天空日射量、水平面夜間日射量は0を出力します。
 //	曜日の祝日判定を行っていません。
 func (df *MsmTarget) ToHAS(out *bytes.Buffer) {
+	// HASP形式は16方位の風向風速を出力
+	W_spd16, W_dir16 := df.wind16()
+
 	for d := 0; d < 365; d++ {
 		off := d * 24
 
@@ -137,12 +248,12 @@ func (df *MsmTarget) ToHAS(out *bytes.Buffer) {
 
 		// 風向 (0:無風,1:NNE,...,16:N)
 		for h := 0; h < 24; h++ {
-			w_dir := int(df.W_dir[off+h]/22.5) + 1
+			w_dir := int(W_dir16[off+h]/22.5) + 1
 			if w_dir == 0 {
 				// 真北の場合を0から16へ変更
 				w_dir = 16
 			}
-			if df.W_spd[off+h] == 0 {
+			if W_spd16[off+h] == 0 {
 				w_dir = 0 // 無風の場合は0
 			}
 
@@ -152,7 +263,7 @@ func (df *MsmTarget) ToHAS(out *bytes.Buffer) {
 
 		// 風速 (0.1m/s)
 		for h := 0; h < 24; h++ {
-			w_spd := int(df.W_dir[off+h] * 10)
+			w_spd := int(W_spd16[off+h] * 10)
 			out.Write([]byte(fmt.Sprintf("%3d", w_spd)))
 		}
 		out.Write([]byte(fmt.Sprintf("%s7\n", day_signature)))
@@ -174,12 +285,21 @@ func (df *MsmTarget) ToHAS(out *bytes.Buffer) {
 //	 - N7: Dew Point Temperature [C]
 //	 - N8: Relative Humidity [%]
 //	 - N9: Atmospheric Station Pressure [Pa]
-//	 - N13: Horizontal Infrared Radiation from Sky [Wh/m2]
-//	 - N14: Global Horizontal Radiation [Wh/m2]
-//	 - N15: Direct Normal Radiation [Wh/m2]
-//	 - N16: Diffuse Horizontal Radiation [Wh/m2]
+//	 - N12: Horizontal Infrared Radiation from Sky [Wh/m2]
+//	 - N13: Global Horizontal Radiation [Wh/m2]
+//	 - N14: Direct Normal Radiation [Wh/m2]
+//	 - N15: Diffuse Horizontal Radiation [Wh/m2]
+//	 - N16: Global Horizontal Illuminance [lux] (CalcIlluminance で照度を計算した場合)
+//	 - N17: Direct Normal Illuminance [lux] (CalcIlluminance で照度を計算した場合)
+//	 - N18: Diffuse Horizontal Illuminance [lux] (CalcIlluminance で照度を計算した場合)
+//	 - N19: Zenith Luminance [Cd/m2] (CalcIlluminance で照度を計算した場合)
 //	 - N20: Wind Direction [degrees]
 //	 - N21: Wind Speed [m/s]
+//	 - N22: Total Sky Cover [tenths] (CalcCloudCover で雲量を計算した場合)
+//	 - N23: Opaque Sky Cover [tenths] (CalcCloudCover で雲量を計算した場合。全雲量と同じ値)
+//	 - N30: Snow Depth [cm] (CalcSnow で積雪を計算した場合)
+//	 - N31: Days Since Last Snowfall (CalcSnow で積雪を計算した場合)
+//	 - N32: Albedo (CalcSnow で積雪を計算した場合)
 //	 - N33: Liquid Precipitation Depth [mm/h]
 func (msm *MsmTarget) ToEPW(out *bytes.Buffer, lat float64, lon float64) {
 
@@ -188,16 +308,30 @@ func (msm *MsmTarget) ToEPW(out *bytes.Buffer, lat float64, lon float64) {
 	out.Write([]byte(fmt.Sprintf("LOCATION,-,-,JPN,-,-,%.2f,%.2f,9.0,0.0\n", lat, lon)))
 
 	// DESIGN CONDITION
-	// 設計条件なし
-	out.Write([]byte("DESIGN CONDITIONS,0\n"))
+	if msm.Design != nil {
+		msm.Design.ToEPWHeader(out)
+	} else {
+		// 設計条件なし
+		out.Write([]byte("DESIGN CONDITIONS,0\n"))
+	}
 
 	// TYPICAL/EXTREME PERIODS
-	// 期間指定なし
-	out.Write([]byte("TYPICAL/EXTREME PERIODS,0\n"))
+	// 季節ごとの代表週・極端週
+	// 期間は月日のみで表すため、複数年のデータでは月日ごとの平均年から選ぶ
+	if len(msm.years()) > 1 {
+		log.Println("複数年のデータのため、EPWの TYPICAL/EXTREME PERIODS には平均年の代表週・極端週を出力します (--periods_output で年ごとに保存できます)")
+		TypicalExtremePeriodsToEPWHeader(out, msm.TypicalExtremePeriodsMeanYear())
+	} else {
+		TypicalExtremePeriodsToEPWHeader(out, msm.TypicalExtremePeriods())
+	}
 
 	// GROUND TEMPERATURES
-	// 地中温度無し
-	out.Write([]byte("GROUND TEMPERATURES,0\n"))
+	if msm.Ground != nil {
+		msm.Ground.ToEPWHeader(out)
+	} else {
+		// 地中温度無し
+		out.Write([]byte("GROUND TEMPERATURES,0\n"))
+	}
 
 	// HOLIDAYS/DAYLIGHT SAVINGS
 	// 休日/サマータイム
@@ -213,6 +347,29 @@ func (msm *MsmTarget) ToEPW(out *bytes.Buffer, lat float64, lon float64) {
 	out.Write([]byte("DATA PERIODS,1,1,Data,Sunday,1/1,12/31\n"))
 
 	for i := 0; i < len(msm.date); i++ {
+		// 照度・天頂輝度
+		IL_GH, IL_DN, IL_SH, ZL := 999999, 999999, 999999, 9999
+		if msm.IL != nil {
+			IL_GH = int(msm.IL[i].GH)
+			IL_DN = int(msm.IL[i].DN)
+			IL_SH = int(msm.IL[i].SH)
+			ZL = int(msm.IL[i].ZL)
+		}
+
+		// 雲量
+		CC := 99
+		if msm.CC != nil {
+			CC = int(math.Round(msm.CC[i]))
+		}
+
+		// 積雪深・降雪後の経過日数・地表面反射率
+		SD, DSLS, ALB := "999", "99", "999"
+		if msm.SN != nil {
+			SD = strconv.Itoa(int(math.Round(msm.SN[i].SD)))
+			DSLS = strconv.Itoa(msm.SN[i].DSLS)
+			ALB = strconv.FormatFloat(msm.SN[i].ALB, 'f', 2, 64)
+		}
+
 		// N1: 年
 		// N2: 月
 		// N3: 日
@@ -227,13 +384,22 @@ func (msm *MsmTarget) ToEPW(out *bytes.Buffer, lat float64, lon float64) {
 		// N13: Global Horizontal Radiation [Wh/m2]
 		// N14: Direct Normal Radiation [Wh/m2]
 		// N15: Diffuse Horizontal Radiation [Wh/m2]
+		// N16: Global Horizontal Illuminance [lux]
+		// N17: Direct Normal Illuminance [lux]
+		// N18: Diffuse Horizontal Illuminance [lux]
+		// N19: Zenith Luminance [Cd/m2]
 		// N20: Wind Direction [degree]
 		// N21: Wind Speed [m/s]
-		// N22-N32: missing
+		// N22: Total Sky Cover [tenths]
+		// N23: Opaque Sky Cover [tenths]
+		// N24-N29: missing
+		// N30: Snow Depth [cm]
+		// N31: Days Since Last Snowfall
+		// N32: Albedo
 		// N33: Liquid Precipitation Depth [mm]
 		// N34: missing
-		// ---------------------------N1 N2 N3 N4 N5 A1N6   N7   N8   N9 N10 N11 N12N13N14N15 N16    N17    N18    N19  N20 N21N22N23 N24  N25 N26 N27       N28 N29   N30N31 N32 N33 N34
-		out.Write([]byte(fmt.Sprintf("%d,%d,%d,%d,60,-,%.1f,%.1f,%.1f,%d,999,9999,%d,%d,%d,%d,999999,999999,999999,9999,%d,%.1f,99,99,9999,99999,9,999999999,999,0.999,999,99,999,%.1f,99\n",
+		// ---------------------------N1 N2 N3 N4 N5 A1N6   N7   N8   N9 N10 N11  N12N13N14N15N16N17N18N19N20N21  N22N23N24  N25   N26N27       N28 N29   N30N31N32N33  N34
+		out.Write([]byte(fmt.Sprintf("%d,%d,%d,%d,60,-,%.1f,%.1f,%.1f,%d,999,9999,%d,%d,%d,%d,%d,%d,%d,%d,%d,%.1f,%d,%d,9999,99999,9,999999999,999,0.999,%s,%s,%s,%.1f,99\n",
 			msm.date[i].Year(),             // N1
 			msm.date[i].Month(),            // N2
 			msm.date[i].Day(),              // N3
@@ -242,12 +408,21 @@ func (msm *MsmTarget) ToEPW(out *bytes.Buffer, lat float64, lon float64) {
 			msm.DT[i],                      // N7
 			msm.RH[i],                      // N8
 			int(msm.PRES[i]),               // N9
-			int(msm.Ld[i]*1000/3.6),        // N13
-			int(msm.DSWRF_est[i]*1000/3.6), // N14
-			int(msm.SR_est[i].DN*1000/3.6), // N15
-			int(msm.SR_est[i].SH*1000/3.6), // N16
-			int(msm.W_dir[i]),              // N20
+			int(msm.Ld[i]*1000/3.6),        // N12
+			int(msm.DSWRF_est[i]*1000/3.6), // N13
+			int(msm.SR_est[i].DN*1000/3.6), // N14
+			int(msm.SR_est[i].SH*1000/3.6), // N15
+			IL_GH,                          // N16
+			IL_DN,                          // N17
+			IL_SH,                          // N18
+			ZL,                             // N19
+			int(math.Round(msm.W_dir[i])),  // N20
 			msm.W_spd[i],                   // N21
+			CC,                             // N22
+			CC,                             // N23
+			SD,                             // N30
+			DSLS,                           // N31
+			ALB,                            // N32
 			msm.APCP01[i],                  // N33
 		)))
 	}
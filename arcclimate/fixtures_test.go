@@ -0,0 +1,27 @@
+package arcclimate
+
+import "time"
+
+// 試験用データの時刻別の値
+type hourlyTestValue struct {
+	TMP    float64 //気温 [℃]
+	RH     float64 //相対湿度 [%]
+	DT     float64 //露点温度 [℃]
+	APCP01 float64 //降水量 [mm/h]
+	W_spd  float64 //風速 [m/s]
+}
+
+// 開始時刻 start から n 時間の時刻 i の値を f で与えた試験用データ
+func hourlyTestTarget(start time.Time, n int, f func(i int) hourlyTestValue) *MsmTarget {
+	msm := &MsmTarget{}
+	for i := 0; i < n; i++ {
+		v := f(i)
+		msm.date = append(msm.date, start.Add(time.Duration(i)*time.Hour))
+		msm.TMP = append(msm.TMP, v.TMP)
+		msm.RH = append(msm.RH, v.RH)
+		msm.DT = append(msm.DT, v.DT)
+		msm.APCP01 = append(msm.APCP01, v.APCP01)
+		msm.W_spd = append(msm.W_spd, v.W_spd)
+	}
+	return msm
+}
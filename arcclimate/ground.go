@@ -0,0 +1,153 @@
+package arcclimate
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+//--------------------------------------
+// 地中温度
+//--------------------------------------
+
+// 地中温度を計算する深さ [m]
+var GroundDepths = []float64{0.5, 2.0, 4.0}
+
+// 土壌の熱拡散率の既定値 [m2/day]
+// EnergyPlus の Weather Converter の既定値 (0.0023226 m2/h) と同じです。
+const DefaultSoilDiffusivity = 0.055742
+
+// 月別の不易層までの地中温度
+type GroundTemperatures struct {
+	Diffusivity float64       //土壌の熱拡散率 [m2/day]
+	Depths      []float64     //深さ [m]
+	Monthly     [][12]float64 //深さごとの月別の地中温度 [℃]
+}
+
+// 気温 TMP の月平均値から Kusuda-Achenbach の式により月別の地中温度を計算します。
+// 地表面温度を年平均気温と月平均気温の年較差の1/2を振幅とする正弦波で近似し、
+// 最寒月の15日を地表面温度が最低となる日とします。各月の値は15日の値です。
+// 複数年のデータの場合、月平均気温は全期間の平均とします。
+//
+// 参照)
+//
+//	Kusuda T, Achenbach PR. Earth temperature and thermal diffusivity at selected stations in the United States.
+//	ASHRAE Transactions 1965; 71(1): 61-75.
+//
+// Args:
+//
+//	diffusivity(float64): 土壌の熱拡散率 (m2/day)
+func (msm *MsmTarget) CalcGroundTemperatures(diffusivity float64) *GroundTemperatures {
+	// 月平均気温
+	var sum [12]float64
+	var n [12]int
+	for i := 0; i < len(msm.date); i++ {
+		m := int(msm.date[i].Month()) - 1
+		sum[m] += msm.TMP[i]
+		n[m]++
+	}
+	TMP := make([]float64, 0, 12)
+	coldest := -1
+	var months [12]float64
+	for m := 0; m < 12; m++ {
+		months[m] = math.NaN()
+		if n[m] > 0 {
+			months[m] = sum[m] / float64(n[m])
+			TMP = append(TMP, months[m])
+			if coldest < 0 || months[m] < months[coldest] {
+				coldest = m
+			}
+		}
+	}
+
+	gt := &GroundTemperatures{
+		Diffusivity: diffusivity,
+		Depths:      append([]float64{}, GroundDepths...),
+		Monthly:     make([][12]float64, len(GroundDepths)),
+	}
+	if coldest < 0 {
+		for k := range gt.Monthly {
+			for m := 0; m < 12; m++ {
+				gt.Monthly[k][m] = math.NaN()
+			}
+		}
+		return gt
+	}
+
+	// 年平均気温と振幅
+	Tmean := mean(TMP)
+	Tmax, Tmin := TMP[0], TMP[0]
+	for _, v := range TMP {
+		Tmax = math.Max(Tmax, v)
+		Tmin = math.Min(Tmin, v)
+	}
+	Tamp := (Tmax - Tmin) / 2
+
+	// 地表面温度が最低となる日 (通日)
+	t0 := float64(time.Date(2001, time.Month(coldest+1), 15, 0, 0, 0, 0, time.UTC).YearDay())
+
+	for k, z := range gt.Depths {
+		for m := 0; m < 12; m++ {
+			t := float64(time.Date(2001, time.Month(m+1), 15, 0, 0, 0, 0, time.UTC).YearDay())
+			gt.Monthly[k][m] = kusudaAchenbach(z, t, Tmean, Tamp, t0, diffusivity)
+		}
+	}
+
+	return gt
+}
+
+// Kusuda-Achenbach の式により深さ z [m]、通日 t の地中温度 [℃] を求めます。
+//
+// Args:
+//
+//	z(float64): 深さ (m)
+//	t(float64): 通日 (日)
+//	Tmean(float64): 年平均地表面温度 (℃)
+//	Tamp(float64): 地表面温度の年振幅 (℃)
+//	t0(float64): 地表面温度が最低となる通日 (日)
+//	alpha(float64): 土壌の熱拡散率 (m2/day)
+func kusudaAchenbach(z float64, t float64, Tmean float64, Tamp float64, t0 float64, alpha float64) float64 {
+	D := math.Sqrt(365 * alpha / math.Pi) // 減衰深さ [m]
+	return Tmean - Tamp*math.Exp(-z/D)*math.Cos(2*math.Pi/365*(t-t0-z/2*math.Sqrt(365/(math.Pi*alpha))))
+}
+
+// EPW形式の GROUND TEMPERATURES 行を出力します。
+// 土壌の熱伝導率・密度・比熱は空欄とします。
+func (gt *GroundTemperatures) ToEPWHeader(out *bytes.Buffer) {
+	out.WriteString(fmt.Sprintf("GROUND TEMPERATURES,%d", len(gt.Depths)))
+	for k, z := range gt.Depths {
+		out.WriteString(",")
+		out.WriteString(strconv.FormatFloat(z, 'f', -1, 64))
+		out.WriteString(",,,")
+		for m := 0; m < 12; m++ {
+			out.WriteString(",")
+			if !math.IsNaN(gt.Monthly[k][m]) {
+				out.WriteString(strconv.FormatFloat(gt.Monthly[k][m], 'f', 2, 64))
+			}
+		}
+	}
+	out.WriteString("\n")
+}
+
+// 月別の地中温度をCSV形式で出力します。
+func (gt *GroundTemperatures) ToCSV(buf *bytes.Buffer) {
+	buf.WriteString("month")
+	for _, z := range gt.Depths {
+		buf.WriteString(",depth_")
+		buf.WriteString(strconv.FormatFloat(z, 'f', -1, 64))
+	}
+	buf.WriteString("\n")
+
+	for m := 0; m < 12; m++ {
+		buf.WriteString(strconv.Itoa(m + 1))
+		for k := range gt.Depths {
+			buf.WriteString(",")
+			if !math.IsNaN(gt.Monthly[k][m]) {
+				buf.WriteString(strconv.FormatFloat(gt.Monthly[k][m], 'f', 2, 64))
+			}
+		}
+		buf.WriteString("\n")
+	}
+}
@@ -0,0 +1,58 @@
+package arcclimate
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Kusuda-Achenbach の式
+func Test_kusudaAchenbach(t *testing.T) {
+	alpha := DefaultSoilDiffusivity
+
+	// 地表面は年平均気温±振幅
+	assert.InDelta(t, 5.0, kusudaAchenbach(0, 15, 15, 10, 15, alpha), 1e-9)
+	assert.InDelta(t, 25.0, kusudaAchenbach(0, 15+365.0/2, 15, 10, 15, alpha), 1e-9)
+
+	// 深さ2mでは振幅が減衰し、最低となる日が遅れる
+	lag := 2.0 / 2 * math.Sqrt(365/(math.Pi*alpha))
+	assert.InDelta(t, 15-10*0.4557, kusudaAchenbach(2, 15+lag, 15, 10, 15, alpha), 1e-3)
+	assert.InDelta(t, 45.65, lag, 0.01)
+}
+
+// 月別の地中温度とEPWのヘッダー
+func Test_CalcGroundTemperatures(t *testing.T) {
+	gt := designTestTarget(2011, 2011).CalcGroundTemperatures(DefaultSoilDiffusivity)
+	assert.Equal(t, []float64{0.5, 2.0, 4.0}, gt.Depths)
+
+	// 深いほど年較差が小さく、年平均は一定
+	for k := range gt.Depths {
+		assert.InDelta(t, 15.0, mean(gt.Monthly[k][:]), 0.3)
+	}
+	rng := func(k int) float64 {
+		max, min := math.Inf(-1), math.Inf(1)
+		for _, v := range gt.Monthly[k] {
+			max = math.Max(max, v)
+			min = math.Min(min, v)
+		}
+		return max - min
+	}
+	assert.True(t, rng(0) > rng(1) && rng(1) > rng(2))
+
+	buf := bytes.NewBuffer(nil)
+	gt.ToEPWHeader(buf)
+	fields := strings.Split(strings.TrimSpace(buf.String()), ",")
+	assert.Equal(t, 2+3*16, len(fields))
+	assert.Equal(t, []string{"GROUND TEMPERATURES", "3", "0.5", "", "", ""}, fields[:6])
+	assert.Equal(t, "2", fields[18])
+	assert.Equal(t, "4", fields[34])
+
+	buf.Reset()
+	gt.ToCSV(buf)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 13, len(lines))
+	assert.Equal(t, "month,depth_0.5,depth_2,depth_4", lines[0])
+}
@@ -0,0 +1,220 @@
+package arcclimate
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//--------------------------------------
+// 地形による日射の遮蔽
+//--------------------------------------
+
+// 推計対象地点から見た地平線の仰角の分布
+type HorizonProfile struct {
+	// 方位角 0°(北)から時計回りに1°ごとの地平線の仰角 (単位:°)
+	Elevation [360]float64
+}
+
+// 3次メッシュの標高データから地平線の仰角を探索する最大距離 [m]
+const horizonSearchDistance = 30000.0
+
+// 3次メッシュの標高データから地平線の仰角を探索する間隔 [m]
+const horizonSearchStep = 250.0
+
+// 地球の半径 [m]
+const earthRadius = 6371000.0
+
+// 大気差の係数
+const refractionCoef = 0.13
+
+// 地平線の仰角の分布を取得します。
+// horizon が "mesh" の場合は、3次メッシュ（1㎞メッシュ）の平均標高データ eleMstr から計算します。
+// それ以外の場合は、方位角と仰角を記述したCSVファイルのパスとして解釈します。
+func NewHorizonProfile(
+	lat float64,
+	lon float64,
+	ele_target float64,
+	horizon string,
+	eleMstr *ElevationMaster) *HorizonProfile {
+
+	if horizon == "mesh" {
+		log.Printf("3次メッシュの標高データから地平線の仰角を計算します")
+		return NewHorizonProfileFromMesh(lat, lon, ele_target, eleMstr)
+	}
+
+	log.Printf("地平線の仰角をファイルから読み込みます %s", horizon)
+	file, err := os.Open(horizon)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	hp, err := ReadHorizonProfile(file)
+	if err != nil {
+		panic(err)
+	}
+	return hp
+}
+
+// 緯度 lat, 経度 lon, 標高 ele_target [m] の地点から見た地平線の仰角を、
+// 3次メッシュ（1㎞メッシュ）の平均標高データ eleMstr から計算します。
+// 周辺の1次メッシュの標高データは必要に応じて読み込みます。
+// 地球の曲率と大気差を考慮しています。
+func NewHorizonProfileFromMesh(lat float64, lon float64, ele_target float64, eleMstr *ElevationMaster) *HorizonProfile {
+	hp := &HorizonProfile{}
+
+	latrad := degreeToRad(lat)
+
+	for az := 0; az < 360; az++ {
+		azrad := degreeToRad(float64(az))
+		max_angle := 0.0
+
+		for d := horizonSearchStep; d <= horizonSearchDistance; d += horizonSearchStep {
+			// 方位角 az の方向に距離 d 離れた地点
+			lat_d := lat + radToDegree(d*math.Cos(azrad)/earthRadius)
+			lon_d := lon + radToDegree(d*math.Sin(azrad)/(earthRadius*math.Cos(latrad)))
+
+			ele_d := eleMstr.Elevation3dOrZero(lat_d, lon_d)
+
+			// 地球の曲率と大気差による見かけの高さの低下
+			drop := d * d / (2 * earthRadius) * (1 - refractionCoef)
+
+			angle := radToDegree(math.Atan2(ele_d-ele_target-drop, d))
+			if angle > max_angle {
+				max_angle = angle
+			}
+		}
+
+		hp.Elevation[az] = max_angle
+	}
+
+	return hp
+}
+
+// 方位角,仰角 [°] の組を記述したCSVを読み込みます。
+// 方位角は北を0°として時計回りとします。数値でない行(ヘッダー等)と'#'で始まる行は無視します。
+// 1°ごとの値は、記述された方位角の間を線形補間して求めます。
+func ReadHorizonProfile(r io.Reader) (*HorizonProfile, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	type point struct {
+		az float64
+		h  float64
+	}
+	points := []point{}
+	for _, record := range records {
+		if len(record) < 2 {
+			continue
+		}
+		az, err1 := strconv.ParseFloat(strings.TrimSpace(record[0]), 64)
+		h, err2 := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		points = append(points, point{math.Mod(math.Mod(az, 360)+360, 360), h})
+	}
+
+	if len(points) == 0 {
+		return nil, fmt.Errorf("地平線の仰角のデータがありません")
+	}
+
+	sort.Slice(points, func(i, j int) bool { return points[i].az < points[j].az })
+
+	hp := &HorizonProfile{}
+	n := len(points)
+	for az := 0; az < 360; az++ {
+		a := float64(az)
+
+		// a を挟む2点(360°で循環)
+		j := sort.Search(n, func(i int) bool { return points[i].az >= a })
+		p1 := points[(j-1+n)%n]
+		p2 := points[j%n]
+
+		span := math.Mod(p2.az-p1.az+360, 360)
+		if span == 0.0 {
+			hp.Elevation[az] = p2.h
+			continue
+		}
+		t := math.Mod(a-p1.az+360, 360) / span
+		hp.Elevation[az] = p1.h + (p2.h-p1.h)*t
+	}
+
+	return hp, nil
+}
+
+// 方位角 A [°] における地平線の仰角 [°] を線形補間して返します。
+func (hp *HorizonProfile) At(A float64) float64 {
+	A = math.Mod(math.Mod(A, 360)+360, 360)
+	i := int(math.Floor(A))
+	t := A - float64(i)
+	return hp.Elevation[i%360]*(1-t) + hp.Elevation[(i+1)%360]*t
+}
+
+// 水平面の天空率 [-] を返します。
+// 等方性天空を仮定し、方位ごとの地平線の仰角 h について cos^2(h) を平均して求めます。
+func (hp *HorizonProfile) SkyViewFactor() float64 {
+	var svf float64
+	for az := 0; az < 360; az++ {
+		c := math.Cos(degreeToRad(math.Max(hp.Elevation[az], 0.0)))
+		svf += c * c
+	}
+	return svf / 360
+}
+
+// 地平線の仰角の分布をCSV形式で出力します。
+func (hp *HorizonProfile) ToCSV(buf *bytes.Buffer) {
+	buf.WriteString("azimuth,elevation\n")
+	for az := 0; az < 360; az++ {
+		buf.WriteString(fmt.Sprintf("%d,%s\n", az, strconv.FormatFloat(hp.Elevation[az], 'f', 3, 64)))
+	}
+}
+
+// 地平線の仰角の分布 hp による遮蔽を直散分離結果に反映します。
+// 太陽高度が地平線の仰角を下回る時刻の法線面直達日射量を0とし、
+// 水平面天空日射量には天空率を乗じます。
+// 水平面全天日射量 DSWRF_est, DSWRF_msm は遮蔽後の直達成分と天空成分の和に置き換えます。
+func (msm *MsmTarget) ApplyHorizon(hp *HorizonProfile) {
+	svf := hp.SkyViewFactor()
+	log.Printf(" 天空率 %f", svf)
+
+	for i := 0; i < len(msm.date); i++ {
+		shaded := msm.h[i] < hp.At(msm.A[i])
+		Sinh := math.Max(math.Sin(degreeToRad(msm.h[i])), 0.0)
+
+		if msm.SR_est != nil {
+			if shaded {
+				msm.SR_est[i].DN = 0.0
+			}
+			msm.SR_est[i].SH *= svf
+			if msm.DSWRF_est != nil {
+				msm.DSWRF_est[i] = msm.SR_est[i].DN*Sinh + msm.SR_est[i].SH
+			}
+		}
+		if msm.SR_msm != nil {
+			if shaded && !math.IsNaN(msm.SR_msm[i].DN) {
+				msm.SR_msm[i].DN = 0.0
+			}
+			msm.SR_msm[i].SH *= svf
+			if msm.DSWRF_msm != nil {
+				msm.DSWRF_msm[i] = msm.SR_msm[i].DN*Sinh + msm.SR_msm[i].SH
+			}
+		}
+	}
+
+	msm.Horizon = hp
+}
@@ -0,0 +1,67 @@
+package arcclimate
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 地平線の仰角のCSVの読み込みと補間
+func Test_ReadHorizonProfile(t *testing.T) {
+	hp, err := ReadHorizonProfile(strings.NewReader("azimuth,elevation\n0,10\n90,0\n# comment\n180,20\n270,0\n"))
+	assert.Nil(t, err)
+
+	assert.InDelta(t, 10.0, hp.Elevation[0], 1.0e-9)
+	assert.InDelta(t, 5.0, hp.Elevation[45], 1.0e-9)
+	assert.InDelta(t, 20.0, hp.Elevation[180], 1.0e-9)
+	assert.InDelta(t, 5.0, hp.Elevation[315], 1.0e-9)
+
+	// 1°未満の補間
+	assert.InDelta(t, 10.0-10.0/90*0.5, hp.At(0.5), 1.0e-9)
+	assert.InDelta(t, hp.Elevation[359], hp.At(-1.0), 1.0e-9)
+
+	_, err = ReadHorizonProfile(strings.NewReader("azimuth,elevation\n"))
+	assert.NotNil(t, err)
+}
+
+// 天空率
+func Test_SkyViewFactor(t *testing.T) {
+	hp := &HorizonProfile{}
+	assert.InDelta(t, 1.0, hp.SkyViewFactor(), 1.0e-12)
+
+	for az := 0; az < 360; az++ {
+		hp.Elevation[az] = 30.0
+	}
+	assert.InDelta(t, math.Pow(math.Cos(degreeToRad(30.0)), 2), hp.SkyViewFactor(), 1.0e-12)
+}
+
+// 地平線による遮蔽と水平面全天日射量の再計算
+func Test_ApplyHorizon(t *testing.T) {
+	hp, err := ReadHorizonProfile(strings.NewReader("0,0\n90,0\n180,40\n270,0\n"))
+	assert.Nil(t, err)
+	svf := hp.SkyViewFactor()
+
+	// 南(180°)の太陽高度30°は遮蔽され、東(90°)の太陽高度30°は遮蔽されない
+	msm := &MsmTarget{
+		date:      []time.Time{time.Date(2011, 1, 1, 12, 0, 0, 0, time.UTC), time.Date(2011, 1, 1, 13, 0, 0, 0, time.UTC)},
+		h:         []float64{30, 30},
+		A:         []float64{180, 90},
+		DSWRF_est: []float64{1.5, 1.5},
+		DSWRF_msm: []float64{math.NaN(), 1.5},
+		SR_est:    []SolarRadiation{{DN: 2.0, SH: 0.5}, {DN: 2.0, SH: 0.5}},
+		SR_msm:    []SolarRadiation{{DN: math.NaN(), SH: math.NaN()}, {DN: 2.0, SH: 0.5}},
+	}
+	msm.ApplyHorizon(hp)
+
+	assert.Equal(t, 0.0, msm.SR_est[0].DN)
+	assert.InDelta(t, 0.5*svf, msm.DSWRF_est[0], 1e-9)
+	assert.InDelta(t, 2.0*0.5+0.5*svf, msm.DSWRF_est[1], 1e-9)
+	assert.True(t, math.IsNaN(msm.DSWRF_msm[0]))
+	assert.InDelta(t, msm.DSWRF_est[1], msm.DSWRF_msm[1], 1e-9)
+	for i := range msm.date {
+		assert.InDelta(t, msm.SR_est[i].DN*math.Sin(degreeToRad(msm.h[i]))+msm.SR_est[i].SH, msm.DSWRF_est[i], 1e-9)
+	}
+}
@@ -0,0 +1,140 @@
+package arcclimate
+
+import (
+	"math"
+)
+
+//--------------------------------------
+// 照度の計算
+//--------------------------------------
+
+// 照度に関するデータ
+type Illuminance struct {
+	GH float64 //水平面全天照度 (単位:lx)
+	DN float64 //法線面直射照度 (単位:lx)
+	SH float64 //水平面天空照度 (単位:lx)
+	ZL float64 //天頂輝度 (単位:cd/m2)
+}
+
+// Perez (1990) の発光効率モデルの係数
+// 天空の晴天度 ε の区分ごとに a, b, c, d の順
+//
+// 参照)
+//
+//	Perez R, Ineichen P, Seals R, Michalsky J, Stewart R.
+//	Modeling daylight availability and irradiance components from direct and global irradiance.
+//	Solar Energy 1990; 44(5): 271-289.
+var perezGlobalEfficacy = [8][4]float64{
+	{96.63, -0.47, 11.50, -9.16},
+	{107.54, 0.79, 1.79, -1.19},
+	{98.73, 0.70, 4.40, -6.95},
+	{92.72, 0.56, 8.36, -8.31},
+	{86.73, 0.98, 7.10, -10.94},
+	{88.34, 1.39, 6.06, -7.60},
+	{78.63, 1.47, 4.93, -11.37},
+	{99.65, 1.86, -4.46, -3.15},
+}
+
+var perezDirectEfficacy = [8][4]float64{
+	{57.20, -4.55, -2.98, 117.12},
+	{98.99, -3.46, -1.21, 12.38},
+	{109.83, -4.90, -1.71, -8.81},
+	{110.34, -5.84, -1.99, -4.56},
+	{106.36, -3.97, -1.75, -6.16},
+	{107.19, -1.25, -1.51, -26.73},
+	{105.75, 0.77, -1.26, -34.44},
+	{101.18, 1.58, -1.10, -8.29},
+}
+
+var perezDiffuseEfficacy = [8][4]float64{
+	{97.24, -0.46, 12.00, -8.91},
+	{107.22, 1.15, 0.59, -3.95},
+	{104.97, 2.96, -5.53, -8.77},
+	{102.39, 5.59, -13.95, -13.90},
+	{100.71, 5.94, -22.75, -23.74},
+	{106.42, 3.83, -36.15, -28.83},
+	{141.88, 1.90, -53.24, -14.03},
+	{152.23, 0.35, -45.27, -7.98},
+}
+
+// 天頂輝度の係数は a, c, c', d の順
+var perezZenithLuminance = [8][4]float64{
+	{40.86, 26.77, -29.59, -45.75},
+	{26.58, 14.73, 58.46, -21.25},
+	{19.34, 2.28, 100.00, 0.25},
+	{13.25, -1.39, 124.79, 15.66},
+	{14.47, -5.09, 160.09, 9.13},
+	{19.76, -3.88, 154.61, -19.21},
+	{28.39, -9.67, 151.58, -69.39},
+	{42.91, -19.62, 130.80, -164.08},
+}
+
+// 天空の晴天度 ε の区分の閾値
+var epsBIN = []float64{1.065, 1.23, 1.5, 1.95, 2.8, 4.5, 6.2}
+
+// 直散分離後の日射量と露点温度 DT から照度および天頂輝度を計算します。
+// 太陽位置および大気外法線面日射量は SeparateSolarRadiation で計算済みである必要があります。
+func (msm *MsmTarget) CalcIlluminance() {
+	DSWRF := msm.dswrf()
+	SR := msm.sr()
+
+	msm.IL = make([]Illuminance, len(msm.date))
+
+	for i := 0; i < len(msm.date); i++ {
+		// 可降水量 [cm]
+		var W float64
+		if !math.IsNaN(msm.DT[i]) {
+			W = math.Exp(0.07*msm.DT[i] - 0.075)
+		} else {
+			W = precipitableWater(msm.TMP[i], msm.Pw[i])
+		}
+
+		msm.IL[i] = perezIlluminance(
+			MJ_to_W(DSWRF[i]),
+			MJ_to_W(SR[i].DN),
+			MJ_to_W(SR[i].SH),
+			msm.h[i],
+			MJ_to_W(msm.IN0[i]),
+			W)
+	}
+}
+
+// Perez (1990) の発光効率モデルにより照度および天頂輝度を求めます。
+// Args:
+//
+//	GH(float64): 水平面全天日射量 (W/m2)
+//	DN(float64): 法線面直達日射量 (W/m2)
+//	SH(float64): 水平面天空日射量 (W/m2)
+//	h(float64): 太陽高度角 (deg)
+//	I0(float64): 大気外法線面日射量 (W/m2)
+//	W(float64): 可降水量 (cm)
+func perezIlluminance(GH float64, DN float64, SH float64, h float64, I0 float64, W float64) Illuminance {
+	if h <= 0.0 || math.IsNaN(GH) || SH <= 0.0 {
+		return Illuminance{}
+	}
+
+	// 天頂角 [rad]
+	Z := degreeToRad(90.0 - h)
+	cosZ := math.Cos(Z)
+	Z3 := 1.041 * Z * Z * Z
+
+	// 天空の晴天度
+	eps := ((SH+DN)/SH + Z3) / (1 + Z3)
+
+	// 天空の明るさ
+	delta := SH * relativeAirMass(h) / I0
+
+	k := IndexOf(eps, epsBIN)
+
+	g := perezGlobalEfficacy[k]
+	d := perezDirectEfficacy[k]
+	s := perezDiffuseEfficacy[k]
+	z := perezZenithLuminance[k]
+
+	return Illuminance{
+		GH: math.Max(0.0, GH*(g[0]+g[1]*W+g[2]*cosZ+g[3]*math.Log(delta))),
+		DN: math.Max(0.0, DN*(d[0]+d[1]*W+d[2]*math.Exp(5.73*Z-5)+d[3]*delta)),
+		SH: math.Max(0.0, SH*(s[0]+s[1]*W+s[2]*cosZ+s[3]*math.Log(delta))),
+		ZL: math.Max(0.0, SH*(z[0]+z[1]*cosZ+z[2]*math.Exp(-3*Z)+z[3]*delta)),
+	}
+}
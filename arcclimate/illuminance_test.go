@@ -0,0 +1,25 @@
+package arcclimate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 発光効率モデルのテスト
+func Test_perezIlluminance(t *testing.T) {
+	// 晴天時 (太陽高度60度)
+	il := perezIlluminance(900, 850, 160, 60.0, 1367, 2.0)
+
+	// 全天の発光効率はおおむね 100-120 lm/W
+	assert.True(t, il.GH/900 > 100 && il.GH/900 < 125)
+	assert.True(t, il.DN > 0.0 && il.SH > 0.0 && il.ZL > 0.0)
+
+	// 曇天時は直射照度が0
+	il = perezIlluminance(200, 0, 200, 30.0, 1367, 2.0)
+	assert.Equal(t, 0.0, il.DN)
+	assert.True(t, il.SH > 0.0)
+
+	// 夜間は0
+	assert.Equal(t, Illuminance{}, perezIlluminance(0, 0, 0, -10.0, 1367, 2.0))
+}
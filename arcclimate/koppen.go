@@ -0,0 +1,159 @@
+package arcclimate
+
+//--------------------------------------
+// ケッペンの気候区分
+//--------------------------------------
+
+// 月平均気温 TMP [℃] と月降水量 APCP [mm] からケッペン・ガイガーの気候区分を判定します。
+// 北半球を前提とし、夏季を4月から9月とします。
+//
+// 参照)
+//
+//	Peel MC, Finlayson BL, McMahon TA. Updated world map of the Köppen-Geiger climate classification.
+//	Hydrology and Earth System Sciences 2007; 11: 1633-1644.
+func KoppenClimate(TMP [12]float64, APCP [12]float64) string {
+	var MAT, MAP float64
+	Thot, Tcold := TMP[0], TMP[0]
+	Tmon10 := 0
+	var Psummer, Pwinter float64
+	Pdry := APCP[0]
+	Psdry, Pswet := APCP[3], APCP[3]
+	Pwdry, Pwwet := APCP[0], APCP[0]
+	for m := 0; m < 12; m++ {
+		MAT += TMP[m] / 12
+		MAP += APCP[m]
+		if TMP[m] > Thot {
+			Thot = TMP[m]
+		}
+		if TMP[m] < Tcold {
+			Tcold = TMP[m]
+		}
+		if TMP[m] >= 10 {
+			Tmon10++
+		}
+		if APCP[m] < Pdry {
+			Pdry = APCP[m]
+		}
+		if 4 <= m+1 && m+1 <= 9 {
+			Psummer += APCP[m]
+			if APCP[m] < Psdry {
+				Psdry = APCP[m]
+			}
+			if APCP[m] > Pswet {
+				Pswet = APCP[m]
+			}
+		} else {
+			Pwinter += APCP[m]
+			if APCP[m] < Pwdry {
+				Pwdry = APCP[m]
+			}
+			if APCP[m] > Pwwet {
+				Pwwet = APCP[m]
+			}
+		}
+	}
+
+	// 乾燥限界 [mm] (Peel et al. の Pthreshold の10倍)
+	Pthreshold := 20*MAT + 140
+	if Pwinter >= 0.7*MAP {
+		Pthreshold = 20 * MAT
+	} else if Psummer >= 0.7*MAP {
+		Pthreshold = 20*MAT + 280
+	}
+
+	// 寒帯
+	if Thot < 10 {
+		if Thot > 0 {
+			return "ET"
+		}
+		return "EF"
+	}
+
+	// 乾燥帯
+	if MAP < Pthreshold {
+		c := "BS"
+		if MAP < Pthreshold/2 {
+			c = "BW"
+		}
+		if MAT >= 18 {
+			return c + "h"
+		}
+		return c + "k"
+	}
+
+	// 熱帯
+	if Tcold >= 18 {
+		if Pdry >= 60 {
+			return "Af"
+		}
+		if Pdry >= 100-MAP/25 {
+			return "Am"
+		}
+		return "Aw"
+	}
+
+	// 温帯・冷帯の降水の季節性
+	second := "f"
+	if Psdry < 40 && Psdry < Pwwet/3 {
+		second = "s"
+	} else if Pwdry < Pswet/10 {
+		second = "w"
+	}
+
+	// 夏の気温
+	third := "c"
+	if Thot >= 22 {
+		third = "a"
+	} else if Tmon10 >= 4 {
+		third = "b"
+	}
+
+	if Tcold > 0 {
+		return "C" + second + third
+	}
+	if third == "c" && Tcold < -38 {
+		third = "d"
+	}
+	return "D" + second + third
+}
+
+// ケッペンの気候区分の記号 code の説明を返します。
+func KoppenDescription(code string) string {
+	switch code {
+	case "Af":
+		return "Tropical rainforest"
+	case "Am":
+		return "Tropical monsoon"
+	case "Aw":
+		return "Tropical savanna"
+	case "BWh", "BWk":
+		return "Arid desert"
+	case "BSh", "BSk":
+		return "Semi-arid steppe"
+	case "Cfa":
+		return "Humid subtropical (mild with no dry season, hot summer)"
+	case "Cfb":
+		return "Marine west coastal (warm summer, mild winter, rain all year)"
+	case "Cfc":
+		return "Subpolar oceanic (cool summer, mild winter, rain all year)"
+	case "Cwa", "Cwb", "Cwc":
+		return "Temperate (mild with dry winter)"
+	case "Csa", "Csb", "Csc":
+		return "Mediterranean (mild with dry summer)"
+	case "Dfa":
+		return "Humid continental (hot summer, cold winter, no dry season)"
+	case "Dfb":
+		return "Humid continental (warm summer, cold winter, no dry season)"
+	case "Dfc", "Dfd":
+		return "Subarctic (cool summer, severe winter, no dry season)"
+	case "Dwa", "Dwb", "Dwc", "Dwd":
+		return "Continental (dry winter)"
+	case "Dsa", "Dsb", "Dsc", "Dsd":
+		return "Continental (dry summer)"
+	case "ET":
+		return "Tundra"
+	case "EF":
+		return "Ice cap"
+	}
+	return ""
+}
@@ -0,0 +1,257 @@
+package arcclimate
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+//--------------------------------------
+// 代表期間・極端期間
+//--------------------------------------
+
+// 季節の定義(北半球)
+type Season struct {
+	Name   string //季節名
+	Months []int  //月
+}
+
+// 代表期間・極端期間を求める季節
+var Seasons = []Season{
+	{Name: "Summer", Months: []int{6, 7, 8}},
+	{Name: "Winter", Months: []int{12, 1, 2}},
+	{Name: "Autumn", Months: []int{9, 10, 11}},
+	{Name: "Spring", Months: []int{3, 4, 5}},
+}
+
+// 度日の基準温度 [℃]
+const periodDegreeDayBase = 18.0
+
+// 代表期間・極端期間(週)
+type TypicalExtremePeriod struct {
+	Season    string    //季節名
+	Kind      string    //Typical or Extreme
+	Name      string    //期間名 (EPWのTYPICAL/EXTREME PERIODSの名称)
+	Start     time.Time //開始日
+	End       time.Time //終了日
+	Target    float64   //季節の平均気温 [℃]
+	Value     float64   //週平均気温 [℃]
+	Deviation float64   //週平均気温の季節の平均気温からの偏差 [℃]
+
+	TargetDegreeDays float64 //季節の平均の週積算度日 [℃日]
+	DegreeDays       float64 //週積算度日 [℃日]
+}
+
+// 気温 TMP から季節ごとの代表週・極端週を求めます。
+// IWEC や TMY の EPW と同様に、夏は週平均気温が最も高い週(Extreme)、冬は最も低い週(Extreme)を、
+// 各季節で週平均気温と週積算度日(基準温度18℃)の季節平均からの偏差が最も小さい週(Typical)を選びます。
+// 週は季節内で日付が連続する7日間とします。該当する週がない季節は出力しません。
+// 複数年のデータでは全期間から選びます。年ごとに選ぶ場合は TypicalExtremePeriodsByYear を、
+// 平均年から選ぶ場合は TypicalExtremePeriodsMeanYear を使用してください。
+func (msm *MsmTarget) TypicalExtremePeriods() []TypicalExtremePeriod {
+	return typicalExtremePeriods(msm.dailyTemperatures())
+}
+
+// 年ごとに季節の代表週・極端週を求め、年の順に連結して返します。
+// 冬(12月～2月)は同じ年の1月～2月と12月から選びます。
+func (msm *MsmTarget) TypicalExtremePeriodsByYear() []TypicalExtremePeriod {
+	days := msm.dailyTemperatures()
+
+	periods := []TypicalExtremePeriod{}
+	for i := 0; i < len(days); {
+		j := i
+		for j < len(days) && days[j].date.Year() == days[i].date.Year() {
+			j++
+		}
+		periods = append(periods, typicalExtremePeriods(days[i:j])...)
+		i = j
+	}
+	return periods
+}
+
+// 複数年のデータについて、月日ごとに日平均気温を全年で平均した平均年から季節の代表週・極端週を求めます。
+// 2月29日は除き、平均年の日付は標準年(EA)と同じく1970年(平年)とします。
+// EPWの期間は月日のみで表すため、複数年のEPWヘッダーにはこの期間を出力します。
+func (msm *MsmTarget) TypicalExtremePeriodsMeanYear() []TypicalExtremePeriod {
+	var sum [12][31]float64
+	var count [12][31]int
+	for _, d := range msm.dailyTemperatures() {
+		if d.date.Month() == time.February && d.date.Day() == 29 {
+			continue
+		}
+		sum[d.date.Month()-1][d.date.Day()-1] += d.TMP
+		count[d.date.Month()-1][d.date.Day()-1]++
+	}
+
+	days := []dailyTemperature{}
+	start := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	for t := start; t.Year() == 1970; t = t.AddDate(0, 0, 1) {
+		m, d := t.Month()-1, t.Day()-1
+		if count[m][d] > 0 {
+			days = append(days, dailyTemperature{date: t, TMP: sum[m][d] / float64(count[m][d])})
+		}
+	}
+	return typicalExtremePeriods(days)
+}
+
+// 日平均気温 days から季節ごとの代表週・極端週を求めます。
+func typicalExtremePeriods(days []dailyTemperature) []TypicalExtremePeriod {
+	periods := []TypicalExtremePeriod{}
+	for _, season := range Seasons {
+		inSeason := func(t time.Time) bool {
+			for _, m := range season.Months {
+				if int(t.Month()) == m {
+					return true
+				}
+			}
+			return false
+		}
+
+		// 季節の平均気温と平均日度日
+		var TMP_sum, DD_sum float64
+		var n int
+		for _, d := range days {
+			if inSeason(d.date) {
+				TMP_sum += d.TMP
+				DD_sum += seasonDegreeDay(season.Name, d.TMP)
+				n++
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		TMP_season := TMP_sum / float64(n)
+		DD_season := DD_sum / float64(n) * 7
+
+		// 候補となる週の週平均気温と週積算度日
+		starts := []int{}
+		TMP_week := []float64{}
+		DD_week := []float64{}
+		for i := 0; i+6 < len(days); i++ {
+			ok := true
+			var t, dd float64
+			for k := 0; k < 7; k++ {
+				d := days[i+k]
+				if !inSeason(d.date) || (k > 0 && !sameDay(days[i+k-1].date.AddDate(0, 0, 1), d.date)) {
+					ok = false
+					break
+				}
+				t += d.TMP
+				dd += seasonDegreeDay(season.Name, d.TMP)
+			}
+			if ok {
+				starts = append(starts, i)
+				TMP_week = append(TMP_week, t/7)
+				DD_week = append(DD_week, dd)
+			}
+		}
+		if len(starts) == 0 {
+			continue
+		}
+
+		period := func(kind string, name string, k int) TypicalExtremePeriod {
+			return TypicalExtremePeriod{
+				Season:    season.Name,
+				Kind:      kind,
+				Name:      season.Name + " - " + name,
+				Start:     days[starts[k]].date,
+				End:       days[starts[k]+6].date,
+				Target:    TMP_season,
+				Value:     TMP_week[k],
+				Deviation: TMP_week[k] - TMP_season,
+
+				TargetDegreeDays: DD_season,
+				DegreeDays:       DD_week[k],
+			}
+		}
+
+		// 極端週
+		if season.Name == "Summer" || season.Name == "Winter" {
+			sign := 1.0
+			name := "Week Nearest Max Temperature For Period"
+			if season.Name == "Winter" {
+				sign = -1.0
+				name = "Week Nearest Min Temperature For Period"
+			}
+			e := 0
+			for k := range starts {
+				if sign*TMP_week[k] > sign*TMP_week[e] {
+					e = k
+				}
+			}
+			periods = append(periods, period("Extreme", name, e))
+		}
+
+		// 代表週: 週平均気温と週積算度日の偏差をそれぞれの標準偏差で正規化した和が最小の週
+		_, TMP_std := meanAndStd(TMP_week)
+		_, DD_std := meanAndStd(DD_week)
+		score := func(k int) float64 {
+			s := 0.0
+			if TMP_std > 0 {
+				s += math.Abs(TMP_week[k]-TMP_season) / TMP_std
+			}
+			if DD_std > 0 {
+				s += math.Abs(DD_week[k]-DD_season) / DD_std
+			}
+			return s
+		}
+		t := 0
+		for k := range starts {
+			if score(k) < score(t) {
+				t = k
+			}
+		}
+		periods = append(periods, period("Typical", "Week Nearest Average Temperature For Period", t))
+	}
+
+	return periods
+}
+
+// 季節 season の代表週の選定に用いる日平均気温 TMP の日度日(基準温度18℃)を返します。
+// 夏は冷房度日、冬は暖房度日、中間期は両者の和とします。
+func seasonDegreeDay(season string, TMP float64) float64 {
+	HDD := math.Max(periodDegreeDayBase-TMP, 0)
+	CDD := math.Max(TMP-periodDegreeDayBase, 0)
+	switch season {
+	case "Summer":
+		return CDD
+	case "Winter":
+		return HDD
+	default:
+		return HDD + CDD
+	}
+}
+
+// EPW形式の TYPICAL/EXTREME PERIODS 行を出力します。
+// EPWの期間は月日のみで年を持たないため、1年分の期間を渡してください。
+// 期間は年を含まない 月/日 で表します。
+func TypicalExtremePeriodsToEPWHeader(out *bytes.Buffer, periods []TypicalExtremePeriod) {
+	out.WriteString(fmt.Sprintf("TYPICAL/EXTREME PERIODS,%d", len(periods)))
+	for _, p := range periods {
+		out.WriteString(fmt.Sprintf(",%s,%s,%d/%2d,%d/%2d",
+			p.Name, p.Kind, p.Start.Month(), p.Start.Day(), p.End.Month(), p.End.Day()))
+	}
+	out.WriteString("\n")
+}
+
+// 代表期間・極端期間をCSV形式で出力します。
+func TypicalExtremePeriodsToCSV(buf *bytes.Buffer, periods []TypicalExtremePeriod) {
+	buf.WriteString("season,kind,name,start,end,season_TMP,week_TMP,deviation,season_DD,week_DD\n")
+
+	writeFloat := func(v float64) {
+		buf.WriteString(",")
+		buf.WriteString(strconv.FormatFloat(v, 'f', 2, 64))
+	}
+	for _, p := range periods {
+		buf.WriteString(fmt.Sprintf("%s,%s,%s,%s,%s", p.Season, p.Kind, p.Name,
+			p.Start.Format("2006-01-02"), p.End.Format("2006-01-02")))
+		writeFloat(p.Target)
+		writeFloat(p.Value)
+		writeFloat(p.Deviation)
+		writeFloat(p.TargetDegreeDays)
+		writeFloat(p.DegreeDays)
+		buf.WriteString("\n")
+	}
+}
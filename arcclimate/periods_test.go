@@ -0,0 +1,101 @@
+package arcclimate
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 代表週・極端週
+func Test_TypicalExtremePeriods(t *testing.T) {
+	periods := designTestTarget(2011, 2011).TypicalExtremePeriods()
+	assert.Equal(t, 6, len(periods))
+
+	// 正弦波の最暖日(7月16日頃)・最寒日(1月16日頃)を含む週
+	assert.Equal(t, "Summer - Week Nearest Max Temperature For Period", periods[0].Name)
+	assert.Equal(t, "Extreme", periods[0].Kind)
+	assert.Equal(t, time.July, periods[0].Start.Month())
+	assert.InDelta(t, 16, periods[0].Start.Day()+3, 2)
+	assert.Equal(t, "Typical", periods[1].Kind)
+	assert.True(t, periods[0].Deviation > 0)
+
+	assert.Equal(t, "Winter - Week Nearest Min Temperature For Period", periods[2].Name)
+	assert.Equal(t, time.January, periods[2].Start.Month())
+	assert.InDelta(t, 16, periods[2].Start.Day()+3, 2)
+	assert.True(t, periods[2].Deviation < 0)
+
+	// 中間期は代表週のみ
+	assert.Equal(t, "Autumn", periods[4].Season)
+	assert.Equal(t, "Spring", periods[5].Season)
+	for _, p := range periods {
+		assert.Equal(t, 6*24*time.Hour, p.End.Sub(p.Start))
+	}
+}
+
+// 代表週・極端週のEPWヘッダーとCSV出力
+func Test_TypicalExtremePeriodsOutput(t *testing.T) {
+	periods := []TypicalExtremePeriod{
+		{
+			Season: "Summer", Kind: "Extreme", Name: "Summer - Week Nearest Max Temperature For Period",
+			Start: time.Date(2011, 7, 3, 0, 0, 0, 0, time.UTC), End: time.Date(2011, 7, 9, 0, 0, 0, 0, time.UTC),
+			Target: 25, Value: 28.5, Deviation: 3.5, TargetDegreeDays: 49, DegreeDays: 73.5,
+		},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	TypicalExtremePeriodsToEPWHeader(buf, periods)
+	assert.Equal(t, "TYPICAL/EXTREME PERIODS,1,Summer - Week Nearest Max Temperature For Period,Extreme,7/ 3,7/ 9\n", buf.String())
+
+	buf.Reset()
+	TypicalExtremePeriodsToEPWHeader(buf, nil)
+	assert.Equal(t, "TYPICAL/EXTREME PERIODS,0\n", buf.String())
+
+	buf.Reset()
+	TypicalExtremePeriodsToCSV(buf, periods)
+	assert.Equal(t, "season,kind,name,start,end,season_TMP,week_TMP,deviation,season_DD,week_DD\n"+
+		"Summer,Extreme,Summer - Week Nearest Max Temperature For Period,2011-07-03,2011-07-09,25.00,28.50,3.50,49.00,73.50\n", buf.String())
+}
+
+// 複数年のデータでは年ごとに選ぶ
+func Test_TypicalExtremePeriodsByYear(t *testing.T) {
+	periods := designTestTarget(2011, 2012).TypicalExtremePeriodsByYear()
+	assert.Equal(t, 12, len(periods))
+	for k, p := range periods {
+		year := 2011 + k/6
+		assert.Equal(t, year, p.Start.Year())
+		assert.Equal(t, year, p.End.Year())
+	}
+	assert.Equal(t, designTestTarget(2011, 2011).TypicalExtremePeriods(), periods[:6])
+
+	// 複数年のEPWヘッダーには平均年の期間を出力する
+	// (平年の2年に同じ気温を与えると、平均年の期間の月日は1年分の期間と同じ)
+	msm := designTestTarget(2013, 2014)
+	l := len(msm.date)
+	copy(msm.TMP[l/2:], msm.TMP[:l/2])
+	mean := msm.TypicalExtremePeriodsMeanYear()
+	first := designTestTarget(2013, 2013).TypicalExtremePeriods()
+	assert.Equal(t, 6, len(mean))
+	for k, p := range mean {
+		assert.Equal(t, 1970, p.Start.Year())
+		assert.Equal(t, first[k].Name, p.Name)
+		assert.Equal(t, first[k].Start.YearDay(), p.Start.YearDay())
+		assert.Equal(t, first[k].End.YearDay(), p.End.YearDay())
+		assert.InDelta(t, first[k].Value, p.Value, 1e-9)
+	}
+	header := bytes.NewBuffer(nil)
+	TypicalExtremePeriodsToEPWHeader(header, mean)
+
+	msm.Ld = make([]float64, l)
+	msm.RH = make([]float64, l)
+	msm.DT = make([]float64, l)
+	msm.APCP01 = make([]float64, l)
+	msm.DSWRF_est = make([]float64, l)
+	msm.SR_est = make([]SolarRadiation, l)
+
+	buf := bytes.NewBuffer(nil)
+	msm.ToEPW(buf, 35, 139)
+	assert.Contains(t, buf.String(), "\n"+header.String())
+	assert.NotContains(t, buf.String(), "TYPICAL/EXTREME PERIODS,0")
+}
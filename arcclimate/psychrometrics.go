@@ -0,0 +1,38 @@
+package arcclimate
+
+import (
+	"github.com/udawtr/arcclimate-go/arcclimate/psychrometrics"
+)
+
+//--------------------------------------
+// 湿り空気の状態値の計算
+//--------------------------------------
+
+// 湿り空気の状態値に関するデータ
+type Psychrometrics struct {
+	DT  float64 //露点温度 (単位:℃)
+	TWB float64 //熱力学的湿球温度 (単位:℃)
+	ENT float64 //比エンタルピー (単位:kJ/kg(DA))
+	SV  float64 //比容積 (単位:m3/kg(DA))
+	RHO float64 //湿り空気の密度 (単位:kg/m3)
+}
+
+// 気温 TMP, 重量絶対湿度 MR, 気圧 PRES から湿り空気の状態値を計算します。
+// 露点温度は水蒸気分圧 Pw から飽和水蒸気圧の式を逆算して求めるため、DT と異なり温度範囲の制限はありません。
+func (msm *MsmTarget) CalcPsychrometrics() {
+	msm.PS = make([]Psychrometrics, len(msm.date))
+
+	for i := 0; i < len(msm.date); i++ {
+		TMP := msm.TMP[i]
+		MR := msm.MR[i]
+		PRES := msm.PRES[i]
+
+		msm.PS[i] = Psychrometrics{
+			DT:  psychrometrics.DewPoint(msm.Pw[i]),
+			TWB: psychrometrics.WetBulb(TMP, MR, PRES),
+			ENT: psychrometrics.Enthalpy(TMP, MR),
+			SV:  psychrometrics.SpecificVolume(TMP, MR, PRES),
+			RHO: psychrometrics.Density(TMP, MR, PRES),
+		}
+	}
+}
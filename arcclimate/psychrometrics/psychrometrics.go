@@ -0,0 +1,139 @@
+// 湿り空気の状態値の計算
+//
+// 温度は℃、気圧は Pa、水蒸気分圧は hPa、重量絶対湿度は g/kg(DA) を単位とします。
+// ただし、飽和水蒸気圧 SaturationPressure は絶対温度 [K] を引数とします。
+package psychrometrics
+
+import "math"
+
+// 0℃の絶対温度 [K]
+const ZeroCelsius = 273.15
+
+// 乾燥空気の気体定数 [J/kgK]
+const Rda = 287.042
+
+// 乾燥空気の定圧比熱 [kJ/kgK]
+const Cpa = 1.006
+
+// 水蒸気の定圧比熱 [kJ/kgK]
+const Cpv = 1.86
+
+// 0℃における水の蒸発潜熱 [kJ/kg]
+const Lv = 2501.0
+
+// 絶対温度 T [K] から 飽和水蒸気圧 [hPa] を求めます。
+// Wagner の式を用いています。
+func SaturationPressure(T float64) float64 {
+	return math.Exp(-5800.2206/T+
+		1.3914993-0.048640239*T+
+		0.41764768*0.0001*T*T-
+		0.14452093*0.0000001*T*T*T+
+		6.5459673*math.Log(T)) / 100
+}
+
+// 水蒸気分圧 Pw [hPa] と 気圧 PRES [Pa] から 重量絶対湿度 [g/kg(DA)] を求めます。
+func HumidityRatio(Pw float64, PRES float64) float64 {
+	P := PRES / 100 // hPa
+	return 622.0 * Pw / (P - Pw)
+}
+
+// 気温 TMP [℃] と 気圧 PRES [Pa] から 飽和時の重量絶対湿度 [g/kg(DA)] を求めます。
+func SaturationHumidityRatio(TMP float64, PRES float64) float64 {
+	return HumidityRatio(SaturationPressure(TMP+ZeroCelsius), PRES)
+}
+
+// 重量絶対湿度 MR [g/kg(DA)] と 気圧 PRES [Pa] から 水蒸気分圧 [hPa] を求めます。
+func VaporPressure(MR float64, PRES float64) float64 {
+	P := PRES / 100 // hPa
+	return P * MR / (622.0 + MR)
+}
+
+// 気温 TMP [℃], 重量絶対湿度 MR [g/kg(DA)], 気圧 PRES [Pa] から 相対湿度 [%] を求めます。
+func RelativeHumidity(TMP float64, MR float64, PRES float64) float64 {
+	return VaporPressure(MR, PRES) / SaturationPressure(TMP+ZeroCelsius) * 100
+}
+
+// 水蒸気分圧 Pw [hPa] から 露点温度 [℃] を求めます。
+// 飽和水蒸気圧の式をニュートン法で逆算するため、温度範囲の制限はありません。
+// 水蒸気分圧が0以下の場合は NaN を返します。
+func DewPoint(Pw float64) float64 {
+	if !(Pw > 0.0) {
+		return math.NaN()
+	}
+
+	lnPw := math.Log(Pw)
+
+	// 初期値 (Magnus式)
+	g := math.Log(Pw / 6.112)
+	T := 243.12*g/(17.62-g) + ZeroCelsius
+
+	for i := 0; i < 50; i++ {
+		// ln(eSAT) の T による微分
+		f := math.Log(SaturationPressure(T)) - lnPw
+		df := 5800.2206/(T*T) - 0.048640239 + 2*0.41764768*0.0001*T - 3*0.14452093*0.0000001*T*T + 6.5459673/T
+
+		dT := f / df
+		T -= dT
+		if math.Abs(dT) < 1e-9 {
+			break
+		}
+	}
+
+	return T - ZeroCelsius
+}
+
+// 気温 TMP [℃], 重量絶対湿度 MR [g/kg(DA)], 気圧 PRES [Pa] から 熱力学的湿球温度 [℃] を求めます。
+// ASHRAE Handbook Fundamentals (2017) 1章の式(33),(35)を2分法で解いています。
+// 湿球温度が0℃未満の場合は氷面上の式を用います。
+func WetBulb(TMP float64, MR float64, PRES float64) float64 {
+	if math.IsNaN(TMP) || math.IsNaN(MR) || math.IsNaN(PRES) {
+		return math.NaN()
+	}
+
+	W := MR / 1000 // kg/kg(DA)
+
+	// 湿球温度 Twb における重量絶対湿度 [kg/kg(DA)]
+	w := func(Twb float64) float64 {
+		Ws := SaturationHumidityRatio(Twb, PRES) / 1000
+		if Twb >= 0.0 {
+			return ((Lv-2.326*Twb)*Ws - Cpa*(TMP-Twb)) / (Lv + Cpv*TMP - 4.186*Twb)
+		}
+		return ((2830-0.24*Twb)*Ws - Cpa*(TMP-Twb)) / (2830 + Cpv*TMP - 2.1*Twb)
+	}
+
+	// 湿球温度は露点温度以上、気温以下
+	a := DewPoint(VaporPressure(MR, PRES))
+	if math.IsNaN(a) {
+		a = -100.0
+	}
+	a -= 1.0
+	b := TMP
+	for b-a > 1e-6 {
+		Twb := (a + b) / 2
+		if w(Twb) < W {
+			a = Twb
+		} else {
+			b = Twb
+		}
+	}
+
+	return (a + b) / 2
+}
+
+// 気温 TMP [℃] と 重量絶対湿度 MR [g/kg(DA)] から 比エンタルピー [kJ/kg(DA)] を求めます。
+func Enthalpy(TMP float64, MR float64) float64 {
+	W := MR / 1000 // kg/kg(DA)
+	return Cpa*TMP + W*(Lv+Cpv*TMP)
+}
+
+// 気温 TMP [℃], 重量絶対湿度 MR [g/kg(DA)], 気圧 PRES [Pa] から 比容積 [m3/kg(DA)] を求めます。
+func SpecificVolume(TMP float64, MR float64, PRES float64) float64 {
+	W := MR / 1000 // kg/kg(DA)
+	return Rda * (TMP + ZeroCelsius) * (1 + 1.607858*W) / PRES
+}
+
+// 気温 TMP [℃], 重量絶対湿度 MR [g/kg(DA)], 気圧 PRES [Pa] から 湿り空気の密度 [kg/m3] を求めます。
+func Density(TMP float64, MR float64, PRES float64) float64 {
+	W := MR / 1000 // kg/kg(DA)
+	return (1 + W) / SpecificVolume(TMP, MR, PRES)
+}
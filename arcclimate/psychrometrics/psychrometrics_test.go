@@ -0,0 +1,51 @@
+package psychrometrics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 飽和水蒸気圧
+func Test_SaturationPressure(t *testing.T) {
+	assert.InDelta(t, 6.112, SaturationPressure(ZeroCelsius), 0.005)
+	assert.InDelta(t, 31.69, SaturationPressure(25.0+ZeroCelsius), 0.02)
+	assert.InDelta(t, 1013.25, SaturationPressure(100.0+ZeroCelsius), 1.0)
+}
+
+// 露点温度は飽和水蒸気圧の逆関数
+func Test_DewPoint(t *testing.T) {
+	for _, TMP := range []float64{-70.0, -40.0, -10.0, 0.0, 13.9, 35.0, 60.0} {
+		Pw := SaturationPressure(TMP + ZeroCelsius)
+		assert.InDelta(t, TMP, DewPoint(Pw), 1.0e-6)
+	}
+	assert.True(t, math.IsNaN(DewPoint(0.0)))
+}
+
+// 25℃, 相対湿度50%, 標準気圧の状態値 (ASHRAE Handbook の湿り空気線図による値)
+func Test_MoistAir(t *testing.T) {
+	TMP := 25.0
+	PRES := 101325.0
+	MR := HumidityRatio(SaturationPressure(TMP+ZeroCelsius)*0.5, PRES)
+
+	assert.InDelta(t, 9.88, MR, 0.02)
+	assert.InDelta(t, 50.0, RelativeHumidity(TMP, MR, PRES), 1.0e-9)
+	assert.InDelta(t, 13.9, DewPoint(VaporPressure(MR, PRES)), 0.05)
+	assert.InDelta(t, 17.9, WetBulb(TMP, MR, PRES), 0.1)
+	assert.InDelta(t, 50.3, Enthalpy(TMP, MR), 0.1)
+	assert.InDelta(t, 0.858, SpecificVolume(TMP, MR, PRES), 0.001)
+	assert.InDelta(t, 1.177, Density(TMP, MR, PRES), 0.002)
+}
+
+// 湿球温度
+func Test_WetBulb(t *testing.T) {
+	PRES := 101325.0
+
+	// 飽和時は気温と一致
+	assert.InDelta(t, 20.0, WetBulb(20.0, SaturationHumidityRatio(20.0, PRES), PRES), 1.0e-5)
+
+	// 氷点下
+	Twb := WetBulb(-5.0, 1.0, PRES)
+	assert.True(t, Twb < -5.0 && Twb > DewPoint(VaporPressure(1.0, PRES)))
+}
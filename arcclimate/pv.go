@@ -0,0 +1,374 @@
+package arcclimate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+//--------------------------------------
+// 太陽光発電量の推計
+//--------------------------------------
+
+// 太陽電池アレイの設定
+type PVArray struct {
+	Name               string  `json:"name"`                // アレイ名
+	Tilt               float64 `json:"tilt"`                // 傾斜角 (単位:°, 水平=0)
+	Azimuth            float64 `json:"azimuth"`             // 方位角 (単位:°, 北=0 から時計回り, 南=180)
+	Capacity           float64 `json:"capacity"`            // 標準試験条件における直流出力 (単位:kW)
+	Gamma              float64 `json:"gamma"`               // 最大出力の温度係数 (単位:1/℃)
+	Losses             float64 `json:"losses"`              // 汚れ・配線・ミスマッチ等のシステム損失率 (-)
+	Inverter           float64 `json:"inverter"`            // パワーコンディショナの定格交流出力 (単位:kW, 0の場合は Capacity/1.2)
+	InverterEfficiency float64 `json:"inverter_efficiency"` // パワーコンディショナの定格効率 (-)
+	Albedo             float64 `json:"albedo"`              // 地表面反射率 (-)
+	TemperatureModel   string  `json:"temperature_model"`   // モジュール温度の計算方法 "Faiman" または "SAPM"
+	U0                 float64 `json:"u0"`                  // Faiman モデルの係数 (単位:W/m2K)
+	U1                 float64 `json:"u1"`                  // Faiman モデルの係数 (単位:W/m3sK)
+	SapmA              float64 `json:"sapm_a"`              // SAPM モデルの係数 a (-)
+	SapmB              float64 `json:"sapm_b"`              // SAPM モデルの係数 b (単位:s/m)
+	SapmDT             float64 `json:"sapm_dt"`             // SAPM モデルのモジュール裏面とセルの温度差 (単位:℃)
+}
+
+// 太陽光発電量の推計の設定
+type PVConfig struct {
+	Arrays []PVArray `json:"arrays"`
+}
+
+// 既定値を設定した太陽電池アレイを返します。
+// 温度係数は結晶シリコン、SAPM の係数は架台設置のガラス/セル/ガラスのモジュールの値です。
+func DefaultPVArray() PVArray {
+	return PVArray{
+		Name:               "array",
+		Tilt:               30.0,
+		Azimuth:            180.0,
+		Capacity:           1.0,
+		Gamma:              -0.0037,
+		Losses:             0.14,
+		Inverter:           0.0,
+		InverterEfficiency: 0.96,
+		Albedo:             0.2,
+		TemperatureModel:   "Faiman",
+		U0:                 25.0,
+		U1:                 6.84,
+		SapmA:              -3.47,
+		SapmB:              -0.0594,
+		SapmDT:             3.0,
+	}
+}
+
+// 記述されていない項目に既定値を設定して読み込みます。
+func (array *PVArray) UnmarshalJSON(data []byte) error {
+	type plain PVArray
+	v := plain(DefaultPVArray())
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*array = PVArray(v)
+	return nil
+}
+
+// JSON形式の太陽光発電量の推計の設定を読み込みます。
+func ReadPVConfig(r io.Reader) (*PVConfig, error) {
+	config := &PVConfig{}
+	if err := json.NewDecoder(r).Decode(config); err != nil {
+		return nil, err
+	}
+
+	if len(config.Arrays) == 0 {
+		return nil, fmt.Errorf("太陽電池アレイが設定されていません")
+	}
+	for i, array := range config.Arrays {
+		if array.Capacity <= 0.0 {
+			return nil, fmt.Errorf("%s: 直流出力が正しくありません", array.Name)
+		}
+		if array.TemperatureModel != "Faiman" && array.TemperatureModel != "SAPM" {
+			return nil, fmt.Errorf("%s: モジュール温度の計算方法が正しくありません %s", array.Name, array.TemperatureModel)
+		}
+		if array.Inverter <= 0.0 {
+			config.Arrays[i].Inverter = array.Capacity / 1.2
+		}
+	}
+
+	return config, nil
+}
+
+// 傾斜面日射量 (単位:W/m2)
+type PlaneOfArrayIrradiance struct {
+	Beam   float64 //直達成分
+	Sky    float64 //天空成分
+	Ground float64 //地面反射成分
+}
+
+// 傾斜面全日射量 [W/m2] を返します。
+func (poa PlaneOfArrayIrradiance) Total() float64 {
+	return poa.Beam + poa.Sky + poa.Ground
+}
+
+// Perez (1990) の天空日射の輝度分布モデルの係数 F11, F12, F13
+// 天空の晴天度 ε の区分(epsBIN)ごと
+var perezF1 = [8][3]float64{
+	{-0.0083117, 0.5877285, -0.0620636},
+	{0.1299457, 0.6825954, -0.1513752},
+	{0.3296958, 0.4868735, -0.2210958},
+	{0.5682053, 0.1874525, -0.2951290},
+	{0.8730280, -0.3920403, -0.3616149},
+	{1.1326077, -1.2367284, -0.4118494},
+	{1.0601591, -1.5999137, -0.3589221},
+	{0.6777470, -0.3272588, -0.2504286},
+}
+
+// Perez (1990) の天空日射の輝度分布モデルの係数 F21, F22, F23
+var perezF2 = [8][3]float64{
+	{-0.0596012, 0.0721249, -0.0220216},
+	{-0.0189325, 0.0659650, -0.0288748},
+	{0.0554140, -0.0639588, -0.0260542},
+	{0.1088631, -0.1519229, -0.0139754},
+	{0.2255647, -0.4620442, 0.0012448},
+	{0.2877813, -0.8230357, 0.0558651},
+	{0.2642124, -1.1272340, 0.1310694},
+	{0.1561313, -1.3765031, 0.2506212},
+}
+
+// 傾斜角 tilt [°], 方位角 azimuth [°] の傾斜面日射量を Perez (1990) のモデルにより求めます。
+// Args:
+//
+//	GH(float64): 水平面全天日射量 (W/m2)
+//	DN(float64): 法線面直達日射量 (W/m2)
+//	SH(float64): 水平面天空日射量 (W/m2)
+//	h(float64): 太陽高度角 (deg)
+//	A(float64): 太陽方位角 (deg, 北=0 から時計回り)
+//	I0(float64): 大気外法線面日射量 (W/m2)
+//	tilt(float64): 傾斜角 (deg)
+//	azimuth(float64): 方位角 (deg, 北=0 から時計回り)
+//	albedo(float64): 地表面反射率 (-)
+func transposePerez(
+	GH float64,
+	DN float64,
+	SH float64,
+	h float64,
+	A float64,
+	I0 float64,
+	tilt float64,
+	azimuth float64,
+	albedo float64) PlaneOfArrayIrradiance {
+
+	if h <= 0.0 || math.IsNaN(GH) || math.IsNaN(DN) || math.IsNaN(SH) {
+		return PlaneOfArrayIrradiance{}
+	}
+
+	tiltrad := degreeToRad(tilt)
+	cosTilt := math.Cos(tiltrad)
+	sinTilt := math.Sin(tiltrad)
+
+	// 天頂角 [rad]
+	Z := degreeToRad(90.0 - h)
+	cosZ := math.Cos(Z)
+
+	// 入射角の余弦
+	cosTheta := cosTilt*cosZ + sinTilt*math.Sin(Z)*math.Cos(degreeToRad(A-azimuth))
+
+	poa := PlaneOfArrayIrradiance{
+		Beam:   DN * math.Max(cosTheta, 0.0),
+		Ground: GH * albedo * (1 - cosTilt) / 2,
+	}
+
+	if SH <= 0.0 {
+		return poa
+	}
+
+	// 天空の晴天度
+	Z3 := 1.041 * Z * Z * Z
+	eps := ((SH+DN)/SH + Z3) / (1 + Z3)
+
+	// 天空の明るさ
+	delta := SH * relativeAirMass(h) / I0
+
+	k := IndexOf(eps, epsBIN)
+	F1 := math.Max(0.0, perezF1[k][0]+perezF1[k][1]*delta+perezF1[k][2]*Z)
+	F2 := perezF2[k][0] + perezF2[k][1]*delta + perezF2[k][2]*Z
+
+	a := math.Max(0.0, cosTheta)
+	b := math.Max(math.Cos(degreeToRad(85.0)), cosZ)
+
+	poa.Sky = math.Max(0.0, SH*((1-F1)*(1+cosTilt)/2+F1*a/b+F2*sinTilt))
+
+	return poa
+}
+
+// 傾斜面日射量 G [W/m2], 気温 TMP [℃], 風速 ws [m/s] から Faiman (2008) のモデルによりモジュール温度 [℃] を求めます。
+func moduleTemperatureFaiman(G float64, TMP float64, ws float64, U0 float64, U1 float64) float64 {
+	return TMP + G/(U0+U1*ws)
+}
+
+// 傾斜面日射量 G [W/m2], 気温 TMP [℃], 風速 ws [m/s] から
+// Sandia Array Performance Model (King et al. 2004) によりセル温度 [℃] を求めます。
+func moduleTemperatureSAPM(G float64, TMP float64, ws float64, a float64, b float64, dT float64) float64 {
+	Tm := G*math.Exp(a+b*ws) + TMP
+	return Tm + G/1000*dT
+}
+
+// PVWatts (Dobos 2014) の直流出力モデル
+// 傾斜面日射量 G [W/m2], セル温度 Tc [℃] における直流出力 [kW] を求めます。
+// Pdc0 は標準試験条件における直流出力 [kW]、gamma は最大出力の温度係数 [1/℃] です。
+func pvwattsDC(G float64, Tc float64, Pdc0 float64, gamma float64) float64 {
+	return math.Max(0.0, G/1000*Pdc0*(1+gamma*(Tc-25)))
+}
+
+// PVWatts (Dobos 2014) のパワーコンディショナのモデル
+// 直流入力 Pdc [kW] に対する交流出力 [kW] を求めます。
+// Pac0 は定格交流出力 [kW]、etaNom は定格効率 [-] です。定格を超える出力はクリッピングされます。
+func pvwattsInverter(Pdc float64, Pac0 float64, etaNom float64) float64 {
+	const etaRef = 0.9637
+
+	if Pdc <= 0.0 {
+		return 0.0
+	}
+
+	Pdc0 := Pac0 / etaNom
+	zeta := Pdc / Pdc0
+	eta := etaNom / etaRef * (-0.0162*zeta - 0.0059/zeta + 0.9858)
+
+	return math.Max(0.0, math.Min(eta*Pdc, Pac0))
+}
+
+// 太陽光発電量の推計結果
+type PVResult struct {
+	date   []time.Time
+	Arrays []PVArray
+
+	// アレイごとの時別値
+	POA [][]float64 //傾斜面全日射量 (単位:W/m2)
+	TC  [][]float64 //セル温度 (単位:℃)
+	DC  [][]float64 //直流発電量 (単位:kWh)
+	AC  [][]float64 //交流発電量 (単位:kWh)
+}
+
+// 太陽電池アレイ arrays の時別の発電量を推計します。
+// 日射量は dswrf() で選択される日射量とその直散分離結果を、モジュール温度には気温 TMP と風速 W_spd を用います。
+func (msm *MsmTarget) SimulatePV(arrays []PVArray) *PVResult {
+	DSWRF := msm.dswrf()
+	SR := msm.sr()
+
+	l := len(msm.date)
+	res := &PVResult{
+		date:   msm.date,
+		Arrays: arrays,
+		POA:    make([][]float64, len(arrays)),
+		TC:     make([][]float64, len(arrays)),
+		DC:     make([][]float64, len(arrays)),
+		AC:     make([][]float64, len(arrays)),
+	}
+
+	for j, array := range arrays {
+		res.POA[j] = make([]float64, l)
+		res.TC[j] = make([]float64, l)
+		res.DC[j] = make([]float64, l)
+		res.AC[j] = make([]float64, l)
+
+		for i := 0; i < l; i++ {
+			poa := transposePerez(
+				MJ_to_W(DSWRF[i]),
+				MJ_to_W(SR[i].DN),
+				MJ_to_W(SR[i].SH),
+				msm.h[i],
+				msm.A[i],
+				MJ_to_W(msm.IN0[i]),
+				array.Tilt,
+				array.Azimuth,
+				msm.albedo(i, array.Albedo))
+			G := poa.Total()
+
+			var Tc float64
+			if array.TemperatureModel == "SAPM" {
+				Tc = moduleTemperatureSAPM(G, msm.TMP[i], msm.W_spd[i], array.SapmA, array.SapmB, array.SapmDT)
+			} else {
+				Tc = moduleTemperatureFaiman(G, msm.TMP[i], msm.W_spd[i], array.U0, array.U1)
+			}
+
+			// 1時間の平均出力[kW]を発電量[kWh]とする
+			Pdc := pvwattsDC(G, Tc, array.Capacity, array.Gamma) * (1 - array.Losses)
+
+			res.POA[j][i] = G
+			res.TC[j][i] = Tc
+			res.DC[j][i] = Pdc
+			res.AC[j][i] = pvwattsInverter(Pdc, array.Inverter, array.InverterEfficiency)
+		}
+	}
+
+	return res
+}
+
+// 年月別のアレイごとの交流発電量 [kWh] を集計します。
+// 年月は古い順に並びます。
+func (res *PVResult) Monthly() ([]YearMonth, [][]float64) {
+	months := []YearMonth{}
+	values := [][]float64{}
+
+	for i := 0; i < len(res.date); i++ {
+		ym := YearMonth{res.date[i].Year(), int(res.date[i].Month())}
+		if len(months) == 0 || months[len(months)-1] != ym {
+			months = append(months, ym)
+			values = append(values, make([]float64, len(res.Arrays)))
+		}
+		for j := range res.Arrays {
+			values[len(values)-1][j] += res.AC[j][i]
+		}
+	}
+
+	return months, values
+}
+
+// 時別の推計結果をCSV形式で出力します。
+func (res *PVResult) ToCSV(buf *bytes.Buffer) {
+	buf.WriteString("date")
+	for _, array := range res.Arrays {
+		buf.WriteString(fmt.Sprintf(",%s_POA,%s_TC,%s_DC,%s_AC", array.Name, array.Name, array.Name, array.Name))
+	}
+	buf.WriteString(",total_AC\n")
+
+	writeFloat := func(v float64) {
+		buf.WriteString(",")
+		buf.WriteString(strconv.FormatFloat(v, 'f', 4, 64))
+	}
+	for i := 0; i < len(res.date); i++ {
+		buf.WriteString(res.date[i].Format("2006-01-02 15:04:05"))
+		var total float64
+		for j := range res.Arrays {
+			writeFloat(res.POA[j][i])
+			writeFloat(res.TC[j][i])
+			writeFloat(res.DC[j][i])
+			writeFloat(res.AC[j][i])
+			total += res.AC[j][i]
+		}
+		writeFloat(total)
+		buf.WriteString("\n")
+	}
+}
+
+// 年月別の交流発電量 [kWh] をCSV形式で出力します。
+func (res *PVResult) MonthlyToCSV(buf *bytes.Buffer) {
+	buf.WriteString("year,month")
+	for _, array := range res.Arrays {
+		buf.WriteString(",")
+		buf.WriteString(array.Name)
+	}
+	buf.WriteString(",total\n")
+
+	months, values := res.Monthly()
+	for k, ym := range months {
+		buf.WriteString(fmt.Sprintf("%d,%d", ym.Year, ym.Month))
+		var total float64
+		for _, v := range values[k] {
+			buf.WriteString(",")
+			buf.WriteString(strconv.FormatFloat(v, 'f', 2, 64))
+			total += v
+		}
+		buf.WriteString(",")
+		buf.WriteString(strconv.FormatFloat(total, 'f', 2, 64))
+		buf.WriteString("\n")
+	}
+}
@@ -0,0 +1,88 @@
+package arcclimate
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 傾斜面日射量のテスト
+func Test_transposePerez(t *testing.T) {
+	h := 50.0
+	DN := 700.0
+	SH := 150.0
+	GH := DN*math.Sin(degreeToRad(h)) + SH
+
+	// 水平面では水平面全天日射量と一致する
+	poa := transposePerez(GH, DN, SH, h, 180.0, 1367.0, 0.0, 180.0, 0.2)
+	assert.InDelta(t, GH, poa.Total(), 1.0e-6)
+	assert.Equal(t, 0.0, poa.Ground)
+
+	// 太陽に正対する面の直達成分は法線面直達日射量と一致する
+	poa = transposePerez(GH, DN, SH, h, 180.0, 1367.0, 90.0-h, 180.0, 0.2)
+	assert.InDelta(t, DN, poa.Beam, 1.0e-6)
+	assert.True(t, poa.Sky > 0.0 && poa.Ground > 0.0)
+
+	// 北向きの鉛直面には直達日射が当たらない
+	poa = transposePerez(GH, DN, SH, h, 180.0, 1367.0, 90.0, 0.0, 0.2)
+	assert.Equal(t, 0.0, poa.Beam)
+
+	// 夜間
+	assert.Equal(t, PlaneOfArrayIrradiance{}, transposePerez(0, 0, 0, -5.0, 0.0, 1367.0, 30.0, 180.0, 0.2))
+}
+
+// モジュール温度と直流出力のテスト
+func Test_pvwattsDC(t *testing.T) {
+	// 標準試験条件
+	assert.InDelta(t, 5.0, pvwattsDC(1000, 25.0, 5.0, -0.0037), 1.0e-9)
+
+	// 高温時の出力低下
+	Tc := moduleTemperatureFaiman(1000, 30.0, 1.0, 25.0, 6.84)
+	assert.InDelta(t, 30.0+1000/31.84, Tc, 1.0e-9)
+	assert.InDelta(t, 5.0*(1-0.0037*(Tc-25)), pvwattsDC(1000, Tc, 5.0, -0.0037), 1.0e-9)
+
+	// SAPM: 無風時のセル温度は気温より十分高い
+	assert.True(t, moduleTemperatureSAPM(1000, 25.0, 0.0, -3.47, -0.0594, 3.0) > 50.0)
+}
+
+// パワーコンディショナのテスト
+func Test_pvwattsInverter(t *testing.T) {
+	// 定格付近の効率
+	assert.InDelta(t, 0.96, pvwattsInverter(4.0/0.96, 4.0, 0.96)/(4.0/0.96), 0.005)
+
+	// クリッピング
+	assert.Equal(t, 4.0, pvwattsInverter(6.0, 4.0, 0.96))
+
+	// 入力なし
+	assert.Equal(t, 0.0, pvwattsInverter(0.0, 4.0, 0.96))
+}
+
+// 設定ファイルの読込
+func Test_ReadPVConfig(t *testing.T) {
+	config, err := ReadPVConfig(strings.NewReader(`{"arrays": [
+		{"name": "south", "capacity": 6.0, "losses": 0.0},
+		{"name": "west", "tilt": 20, "azimuth": 270, "capacity": 3.0, "inverter": 2.5, "temperature_model": "SAPM"}
+	]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(config.Arrays))
+
+	south := config.Arrays[0]
+	assert.Equal(t, 30.0, south.Tilt)
+	assert.Equal(t, 180.0, south.Azimuth)
+	assert.Equal(t, 0.0, south.Losses)
+	assert.Equal(t, 5.0, south.Inverter)
+	assert.Equal(t, "Faiman", south.TemperatureModel)
+
+	west := config.Arrays[1]
+	assert.Equal(t, 270.0, west.Azimuth)
+	assert.Equal(t, 2.5, west.Inverter)
+	assert.Equal(t, 0.14, west.Losses)
+
+	_, err = ReadPVConfig(strings.NewReader(`{"arrays": [{"capacity": 1.0, "temperature_model": "NOCT"}]}`))
+	assert.NotNil(t, err)
+
+	_, err = ReadPVConfig(strings.NewReader(`{"arrays": []}`))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,134 @@
+package arcclimate
+
+import (
+	"math"
+
+	"github.com/udawtr/arcclimate-go/arcclimate/psychrometrics"
+)
+
+//--------------------------------------
+// 降雪・積雪深・地表面反射率
+//--------------------------------------
+
+// 降水を雨と雪に分ける湿球温度の範囲 [℃]
+// 下限以下はすべて雪、上限以上はすべて雨とし、その間は湿球温度に応じて線形に雪の割合を減らします。
+const (
+	SnowWetBulbLower = 0.0
+	SnowWetBulbUpper = 2.0
+)
+
+// 積雪の融雪係数 [mm/(℃・日)] (デグリーデー法)
+const SnowMeltFactor = 4.0
+
+// 融雪が生じる気温の下限 [℃]
+const SnowMeltBaseTemperature = 0.0
+
+// 積雪の平均密度 [kg/m3] (積雪水量から積雪深を求める)
+const SnowDensity = 250.0
+
+// 降雪とみなす1時間の降雪水量の下限 [mm]
+const SnowfallThreshold = 0.1
+
+// 地表面反射率
+const (
+	GroundAlbedo       = 0.2  //積雪がない場合
+	FreshSnowAlbedo    = 0.85 //新雪
+	OldSnowAlbedo      = 0.5  //十分に時間が経過した積雪
+	SnowAlbedoDecay    = 5.0  //積雪の反射率が低下する時定数 [日]
+	SnowCoverFullDepth = 10.0 //地表面が積雪で完全に覆われる積雪深 [cm]
+)
+
+// EPW形式の降雪後の経過日数の上限 [日]
+const maxDaysSinceLastSnowfall = 88
+
+// 降雪・積雪に関するデータ
+type Snow struct {
+	SNOW float64 //参照時刻の前1時間の降雪水量 (単位:mm)
+	SWE  float64 //積雪水量 (単位:mm)
+	SD   float64 //積雪深 (単位:cm)
+	DSLS int     //降雪後の経過日数 (単位:日, 上限88)
+	ALB  float64 //地表面反射率 (-)
+}
+
+// 湿球温度 TWB [℃] から降水のうち雪の割合 [-] を返します。
+func snowFraction(TWB float64) float64 {
+	if TWB <= SnowWetBulbLower {
+		return 1.0
+	}
+	if TWB >= SnowWetBulbUpper {
+		return 0.0
+	}
+	return (SnowWetBulbUpper - TWB) / (SnowWetBulbUpper - SnowWetBulbLower)
+}
+
+// 積雪深 SD [cm] と降雪後の経過時間 hours [h] から地表面反射率 [-] を求めます。
+// 積雪の反射率は降雪後の経過時間とともに新雪の値から指数関数的に低下し、
+// 積雪深が SnowCoverFullDepth 未満の場合は積雪のない地表面と面積比で按分します。
+func snowAlbedo(SD float64, hours float64) float64 {
+	if SD <= 0 {
+		return GroundAlbedo
+	}
+	ALB_snow := OldSnowAlbedo + (FreshSnowAlbedo-OldSnowAlbedo)*math.Exp(-hours/24/SnowAlbedoDecay)
+	f := math.Min(SD/SnowCoverFullDepth, 1.0)
+	return GroundAlbedo*(1-f) + ALB_snow*f
+}
+
+// 降水を雨と雪に分け、積雪深と地表面反射率を計算します。
+// 降雪は湿球温度により判定し、積雪はデグリーデー法で融雪を計算する積雪水量のモデルで求めます。
+// 期間の始めの積雪の状態は不明のため、1回目の計算の期間末の積雪水量と降雪後の経過時間を初期値として再計算します。
+func (msm *MsmTarget) CalcSnow() {
+	SWE, hours := msm.simulateSnow(0.0, math.Inf(1))
+	msm.simulateSnow(SWE, hours)
+}
+
+// 初期の積雪水量 SWE [mm] と降雪後の経過時間 hours [h] から積雪を計算し、期間末の積雪水量と経過時間を返します。
+func (msm *MsmTarget) simulateSnow(SWE float64, hours float64) (float64, float64) {
+	msm.SN = make([]Snow, len(msm.date))
+
+	for i := 0; i < len(msm.date); i++ {
+		if i > 0 {
+			hours += msm.date[i].Sub(msm.date[i-1]).Hours()
+		}
+
+		// 降雪水量
+		TWB := psychrometrics.WetBulb(msm.TMP[i], msm.MR[i], msm.PRES[i])
+		SNOW := msm.APCP01[i] * snowFraction(TWB)
+		if SNOW >= SnowfallThreshold {
+			hours = 0
+		}
+
+		// 積雪水量 (降雪と融雪)
+		SWE += SNOW
+		melt := SnowMeltFactor / 24 * math.Max(msm.TMP[i]-SnowMeltBaseTemperature, 0)
+		SWE = math.Max(SWE-melt, 0)
+
+		// 積雪深 [cm]
+		SD := SWE / SnowDensity * 100
+
+		DSLS := maxDaysSinceLastSnowfall
+		if hours < float64(maxDaysSinceLastSnowfall*24) {
+			DSLS = int(hours / 24)
+		}
+
+		msm.SN[i] = Snow{
+			SNOW: SNOW,
+			SWE:  SWE,
+			SD:   SD,
+			DSLS: DSLS,
+			ALB:  snowAlbedo(SD, hours),
+		}
+	}
+
+	return SWE, hours
+}
+
+// 積雪のない地表面の反射率を albedo とした時刻 i の地表面反射率を返します。
+// 積雪を計算していない場合は albedo を返します。
+func (msm *MsmTarget) albedo(i int, albedo float64) float64 {
+	if msm.SN == nil {
+		return albedo
+	}
+	// 積雪のない部分の反射率を GroundAlbedo から albedo に置き換え
+	f := math.Min(msm.SN[i].SD/SnowCoverFullDepth, 1.0)
+	return msm.SN[i].ALB + (albedo-GroundAlbedo)*(1-f)
+}
@@ -0,0 +1,126 @@
+package arcclimate
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 降水の雪の割合
+func Test_snowFraction(t *testing.T) {
+	assert.Equal(t, 1.0, snowFraction(-3))
+	assert.Equal(t, 0.5, snowFraction(1))
+	assert.Equal(t, 0.0, snowFraction(2))
+}
+
+// 積雪の地表面反射率
+func Test_snowAlbedo(t *testing.T) {
+	assert.Equal(t, GroundAlbedo, snowAlbedo(0, 0))
+	assert.InDelta(t, FreshSnowAlbedo, snowAlbedo(20, 0), 1e-9)
+	assert.InDelta(t, OldSnowAlbedo, snowAlbedo(20, math.Inf(1)), 1e-9)
+	// 積雪深 5cm は半分を覆う
+	assert.InDelta(t, (GroundAlbedo+FreshSnowAlbedo)/2, snowAlbedo(5, 0), 1e-9)
+}
+
+// 1月1日から10日間の試験用データ
+// 1日目の0時から10時間 -5℃で 2.5 mm/h の降雪、以降は気温 TMP[d] の日が続く
+func snowTestTarget(TMP []float64) *MsmTarget {
+	msm := &MsmTarget{}
+	start := time.Date(2011, 1, 1, 0, 0, 0, 0, time.UTC)
+	for d := 0; d < len(TMP); d++ {
+		for h := 0; h < 24; h++ {
+			msm.date = append(msm.date, start.Add(time.Duration(d*24+h)*time.Hour))
+			if d == 0 && h < 10 {
+				msm.TMP = append(msm.TMP, -5.0)
+				msm.APCP01 = append(msm.APCP01, 2.5)
+			} else {
+				msm.TMP = append(msm.TMP, TMP[d])
+				msm.APCP01 = append(msm.APCP01, 0.0)
+			}
+			msm.MR = append(msm.MR, 2.0)
+			msm.PRES = append(msm.PRES, 101325.0)
+		}
+	}
+	return msm
+}
+
+// 積雪深と融雪
+func Test_CalcSnow(t *testing.T) {
+	// 1日目は -5℃, 2日目以降は 6℃ (1日あたり 24 mm の融雪)
+	msm := snowTestTarget([]float64{-5, -5, 6, 6, 6})
+	msm.CalcSnow()
+
+	// 25 mm の降雪は積雪深 10 cm
+	assert.InDelta(t, 2.5, msm.SN[0].SNOW, 1e-9)
+	assert.InDelta(t, 25.0, msm.SN[23].SWE, 1e-9)
+	assert.InDelta(t, 10.0, msm.SN[23].SD, 1e-9)
+	assert.Equal(t, 0, msm.SN[23].DSLS)
+	assert.Equal(t, 1, msm.SN[24+9].DSLS)
+	assert.True(t, msm.SN[23].ALB > 0.8)
+
+	// 3日目に融け始め、4日目の終わりには消える
+	assert.InDelta(t, 25.0-24.0, msm.SN[3*24-1].SWE, 1e-9)
+	assert.Equal(t, 0.0, msm.SN[4*24-1].SD)
+	assert.Equal(t, GroundAlbedo, msm.SN[4*24-1].ALB)
+
+	// 積雪がある場合の地表面反射率 (積雪のない部分の反射率を置換)
+	assert.InDelta(t, msm.SN[23].ALB, msm.albedo(23, 0.3), 1e-9)
+	assert.InDelta(t, 0.3, msm.albedo(4*24-1, 0.3), 1e-9)
+	assert.Equal(t, 0.3, (&MsmTarget{}).albedo(0, 0.3))
+}
+
+// 湿球温度による雨と雪の判定
+func Test_CalcSnow_Partition(t *testing.T) {
+	// 乾いた空気では気温が正でも湿球温度により雪となる
+	msm := snowTestTarget([]float64{-5, 5})
+	for i := 0; i < 10; i++ {
+		msm.TMP[i] = 2
+	}
+	msm.CalcSnow()
+	assert.InDelta(t, 2.5, msm.SN[0].SNOW, 1e-9)
+
+	// 湿った暖かい空気の降水は雪にならない
+	msm = snowTestTarget([]float64{-5, 5})
+	for i := 0; i < 10; i++ {
+		msm.TMP[i] = 5
+		msm.MR[i] = 5
+	}
+	msm.CalcSnow()
+	assert.Equal(t, 0.0, msm.SN[0].SNOW)
+	assert.Equal(t, 88, msm.SN[0].DSLS)
+}
+
+// 期間末の積雪を初期値として再計算
+func Test_CalcSnow_Carryover(t *testing.T) {
+	// 最後まで氷点下のため、期間の始めにも積雪がある
+	msm := snowTestTarget([]float64{-5, -5, -5})
+	msm.CalcSnow()
+	assert.InDelta(t, 25.0, msm.SN[0].SWE-msm.SN[0].SNOW, 1e-9)
+	assert.InDelta(t, 50.0, msm.SN[len(msm.SN)-1].SWE, 1e-9)
+}
+
+// EPW形式の積雪深・降雪後の経過日数・地表面反射率
+func Test_ToEPW_Snow(t *testing.T) {
+	msm := designTestTarget(2011, 2011)
+	l := len(msm.date)
+	msm.Ld = make([]float64, l)
+	msm.RH = make([]float64, l)
+	msm.DT = make([]float64, l)
+	msm.APCP01 = make([]float64, l)
+	msm.DSWRF_est = make([]float64, l)
+	msm.SR_est = make([]SolarRadiation, l)
+
+	buf := bytes.NewBuffer(nil)
+	msm.ToEPW(buf, 35, 139)
+	assert.Contains(t, buf.String(), ",9999,99999,9,999999999,999,0.999,999,99,999,0.0,99\n")
+
+	msm.CalcSnow()
+	buf.Reset()
+	msm.ToEPW(buf, 35, 139)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.True(t, strings.HasSuffix(lines[len(lines)-1], ",999,0.999,0,88,0.20,0.0,99"))
+}
@@ -37,9 +37,11 @@ func (msm_target *MsmTarget) SeparateSolarRadiation(
 	solpos := get_sun_position(lat, lon, msm_target.date)
 	msm_target.h = make([]float64, len(solpos))
 	msm_target.A = make([]float64, len(solpos))
+	msm_target.IN0 = make([]float64, len(solpos))
 	for i, v := range solpos {
 		msm_target.h[i] = v.h
 		msm_target.A[i] = v.A
+		msm_target.IN0[i] = v.IN0
 	}
 
 	//2種の日射量データについて繰り返し
@@ -0,0 +1,476 @@
+package arcclimate
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/udawtr/arcclimate-go/arcclimate/psychrometrics"
+)
+
+//--------------------------------------
+// EnergyPlus 気象統計(STAT)
+//--------------------------------------
+
+// 16方位の名称(北から時計回り)
+var windDirectionNames = []string{
+	"North", "NNE", "NE", "ENE", "East", "ESE", "SE", "SSE",
+	"South", "SSW", "SW", "WSW", "West", "WNW", "NW", "NNW",
+}
+
+// 設計用外気条件の見出し(EnergyPlus の STAT 形式)
+var (
+	statHeatingNames = []string{"Heating", "ColdestMonth", "DB996", "DB990",
+		"DP996", "HR_DP996", "DB_DP996", "DP990", "HR_DP990", "DB_DP990",
+		"WS004c", "DB_WS004c", "WS010c", "DB_WS010c", "WS_DB996", "WD_DB996"}
+	statCoolingNames = []string{"Cooling", "HottestMonth", "DBR",
+		"DB004", "WB_DB004", "DB010", "WB_DB010", "DB020", "WB_DB020",
+		"WB004", "DB_WB004", "WB010", "DB_WB010", "WB020", "DB_WB020", "WS_DB004", "WD_DB004",
+		"DP004", "HR_DP004", "DB_DP004", "DP010", "HR_DP010", "DB_DP010", "DP020", "HR_DP020", "DB_DP020",
+		"EN004", "DB_EN004", "EN010", "DB_EN010", "EN020", "DB_EN020", "Hrs_8-4_&_DB"}
+	statExtremesNames = []string{"Extremes", "WS010", "WS025", "WS050", "WBmax",
+		"DBmin_mean", "DBmax_mean", "DBmin_stddev", "DBmax_stddev",
+		"DBmin05years", "DBmax05years", "DBmin10years", "DBmax10years",
+		"DBmin20years", "DBmax20years", "DBmin50years", "DBmax50years"}
+)
+
+// EnergyPlus の STAT 形式に準じた気象統計を出力します。
+// 月別の統計値、度日、代表週・極端週、ケッペンの気候区分を含みます。
+// 複数年のデータの場合、月別の値は全期間の集計値、降水量と度日は1年あたりの値とします。
+// 設計用外気条件 Design が未計算の場合は計算します。
+func (msm *MsmTarget) ToSTAT(out *bytes.Buffer, lat float64, lon float64) {
+	dc := msm.Design
+	if dc == nil {
+		dc = msm.CalcDesignConditions()
+	}
+
+	// 月別の添字と年数
+	monthIndex := make([][]int, 12)
+	monthYears := make([]map[int]bool, 12)
+	for m := 0; m < 12; m++ {
+		monthYears[m] = map[int]bool{}
+	}
+	for i := 0; i < len(msm.date); i++ {
+		m := int(msm.date[i].Month()) - 1
+		monthIndex[m] = append(monthIndex[m], i)
+		monthYears[m][msm.date[i].Year()] = true
+	}
+	perYear := func(m int, v float64) float64 {
+		if len(monthYears[m]) == 0 {
+			return math.NaN()
+		}
+		return v / float64(len(monthYears[m]))
+	}
+
+	months := make([]string, 12)
+	for m := 0; m < 12; m++ {
+		months[m] = monthName(m + 1)
+	}
+
+	//
+	// 地点
+	//
+	out.WriteString(fmt.Sprintf(" Statistics for %s\n", locationName(lat, lon)))
+	out.WriteString(" Location -- JPN\n")
+	out.WriteString(fmt.Sprintf("     %s %s {GMT +9.0 Hours}\n", degreeMinute(lat, "N", "S"), degreeMinute(lon, "E", "W")))
+	out.WriteString(fmt.Sprintf(" Elevation -- %5.0fm above sea level\n", msm.Elevation))
+	out.WriteString(fmt.Sprintf(" Standard Pressure at Elevation -- %6.0fPa\n", 101325*math.Pow(1-2.25577e-5*msm.Elevation, 5.2559)))
+	out.WriteString(" Data Source -- ArcClimate\n\n")
+
+	//
+	// 設計用外気条件
+	//
+	heating, cooling, extremes := dc.headerFields()
+	out.WriteString(" - Displaying Design Conditions from \"Climate Design Data 2009 ASHRAE Handbook\"\n")
+	out.WriteString(fmt.Sprintf(" - Computed by ArcClimate from %d year(s) of hourly data\n", dc.Years))
+	for _, rows := range [][2][]string{{statHeatingNames, heating}, {statCoolingNames, cooling}, {statExtremesNames, extremes}} {
+		out.WriteString("\t" + strings.Join(append([]string{"Design Stat"}, rows[0][1:]...), "\t") + "\n")
+		out.WriteString("\t" + strings.Join(rows[1], "\t") + "\n")
+	}
+	out.WriteString("\n")
+
+	//
+	// 乾球温度・露点温度
+	//
+	// 露点温度は温度範囲の制限がない水蒸気分圧 Pw からの値を使用 (DT は±50℃の範囲外で NaN)
+	DP := make([]float64, len(msm.date))
+	for i := range DP {
+		DP[i] = psychrometrics.DewPoint(msm.Pw[i])
+	}
+	for _, item := range []struct {
+		name string
+		list []float64
+	}{{"Dry Bulb temperatures", msm.TMP}, {"Dew Point temperatures", DP}} {
+		out.WriteString(fmt.Sprintf(" - Monthly Statistics for %s °C\n", item.name))
+		writeSTATMonthlyMaxMin(out, msm, item.list, monthIndex, months, 1)
+		out.WriteString("\n")
+	}
+
+	//
+	// 時刻別の乾球温度
+	//
+	out.WriteString(" - Average Hourly Statistics for Dry Bulb temperatures °C\n")
+	writeSTATRow(out, "", months)
+	for hour := 0; hour < 24; hour++ {
+		values := make([]string, 12)
+		for m := 0; m < 12; m++ {
+			var sum float64
+			var n int
+			for _, i := range monthIndex[m] {
+				if msm.date[i].Hour() == hour {
+					sum += msm.TMP[i]
+					n++
+				}
+			}
+			values[m] = statFloat(sum/float64(n), 1)
+		}
+		writeSTATRow(out, fmt.Sprintf("%2d:01-%2d:00", hour, hour+1), values)
+	}
+	out.WriteString("\n")
+
+	//
+	// 相対湿度
+	//
+	out.WriteString(" - Monthly Statistics for Relative Humidity %\n")
+	writeSTATRow(out, "", months)
+	writeSTATRow(out, "Daily Avg", monthlyMeans(msm.RH, monthIndex, 0))
+	out.WriteString("\n")
+
+	//
+	// 風速・風向
+	//
+	out.WriteString(" - Monthly Statistics for Wind Speed m/s\n")
+	writeSTATMonthlyMaxMin(out, msm, msm.W_spd, monthIndex, months, 1)
+	out.WriteString("\n")
+
+	out.WriteString(" - Monthly Wind Direction {Interval 22.5 deg from displayed deg) %\n")
+	writeSTATRow(out, "", months)
+	for k, name := range windDirectionNames {
+		values := make([]string, 12)
+		for m := 0; m < 12; m++ {
+			var n int
+			for _, i := range monthIndex[m] {
+				if int(math.Round(msm.W_dir[i]/22.5))%16 == k {
+					n++
+				}
+			}
+			values[m] = statFloat(float64(n)/float64(len(monthIndex[m]))*100, 0)
+		}
+		writeSTATRow(out, name, values)
+	}
+	out.WriteString("\n")
+
+	//
+	// 日射量
+	//
+	if sr := msm.sr(); sr != nil && len(sr) == len(msm.date) {
+		DSWRF := msm.dswrf()
+		DN := make([]float64, len(sr))
+		SH := make([]float64, len(sr))
+		for i := range sr {
+			DN[i] = sr[i].DN
+			SH[i] = sr[i].SH
+		}
+
+		out.WriteString(" - Monthly Statistics for Solar Radiation (Direct Normal, Diffuse, Global Horizontal) Wh/m²\n")
+		writeSTATRow(out, "", months)
+		dirAvg := make([]string, 12)
+		dirMax := make([]string, 12)
+		dirDay := make([]string, 12)
+		difAvg := make([]string, 12)
+		glbAvg := make([]string, 12)
+		for m := 0; m < 12; m++ {
+			dn := dailySums(msm, DN, monthIndex[m])
+			dirAvg[m] = statFloat(MJ_to_W(mean(dn.values)), 0)
+			k := 0
+			for j := range dn.values {
+				if dn.values[j] > dn.values[k] {
+					k = j
+				}
+			}
+			if len(dn.values) > 0 {
+				dirMax[m] = statFloat(MJ_to_W(dn.values[k]), 0)
+				dirDay[m] = strconv.Itoa(dn.dates[k].Day())
+			}
+			difAvg[m] = statFloat(MJ_to_W(mean(dailySums(msm, SH, monthIndex[m]).values)), 0)
+			glbAvg[m] = statFloat(MJ_to_W(mean(dailySums(msm, DSWRF, monthIndex[m]).values)), 0)
+		}
+		writeSTATRow(out, "Direct Avg", dirAvg)
+		writeSTATRow(out, "Direct Max", dirMax)
+		writeSTATRow(out, "Day", dirDay)
+		writeSTATRow(out, "Diffuse Avg", difAvg)
+		writeSTATRow(out, "Global Avg", glbAvg)
+		out.WriteString("\n")
+	}
+
+	//
+	// 降水量
+	//
+	APCP := make([]float64, 12)
+	out.WriteString(" - Monthly Statistics for Liquid Precipitation mm\n")
+	writeSTATRow(out, "", months)
+	total := make([]string, 12)
+	maxHourly := make([]string, 12)
+	for m := 0; m < 12; m++ {
+		var sum float64
+		max := math.NaN()
+		for _, i := range monthIndex[m] {
+			sum += msm.APCP01[i]
+			if math.IsNaN(max) || msm.APCP01[i] > max {
+				max = msm.APCP01[i]
+			}
+		}
+		APCP[m] = perYear(m, sum)
+		total[m] = statFloat(APCP[m], 0)
+		maxHourly[m] = statFloat(max, 1)
+	}
+	writeSTATRow(out, "Total", total)
+	writeSTATRow(out, "Max Hourly", maxHourly)
+	out.WriteString("\n")
+
+	//
+	// 雲量
+	//
+	if msm.CC != nil {
+		out.WriteString(" - Monthly Statistics for Sky Cover tenths\n")
+		writeSTATRow(out, "", months)
+		writeSTATRow(out, "Daily Avg", monthlyMeans(msm.CC, monthIndex, 1))
+		out.WriteString("\n")
+	}
+
+	//
+	// 地中温度
+	//
+	if msm.Ground != nil {
+		out.WriteString(fmt.Sprintf(" - Monthly Calculated \"undisturbed\" Ground Temperatures** °C (soil diffusivity %g m²/day)\n", msm.Ground.Diffusivity))
+		writeSTATRow(out, "", months)
+		for k, z := range msm.Ground.Depths {
+			values := make([]string, 12)
+			for m := 0; m < 12; m++ {
+				values[m] = statFloat(msm.Ground.Monthly[k][m], 1)
+			}
+			writeSTATRow(out, fmt.Sprintf("%gm", z), values)
+		}
+		out.WriteString("   ** Calculated by the Kusuda-Achenbach model from the monthly mean dry-bulb temperatures.\n\n")
+	}
+
+	//
+	// 度日
+	//
+	days := msm.dailyTemperatures()
+	out.WriteString(" - Monthly Heating/Cooling Degree Days\n")
+	writeSTATRow(out, "", months)
+	annual := map[string]float64{}
+	for _, dd := range []struct {
+		name    string
+		base    float64
+		heating bool
+	}{{"HDD base 10C", 10, true}, {"HDD base 18C", 18, true}, {"CDD base 10C", 10, false}, {"CDD base 18C", 18, false}} {
+		sums := make([]float64, 12)
+		for _, d := range days {
+			v := d.TMP - dd.base
+			if dd.heating {
+				v = -v
+			}
+			sums[int(d.date.Month())-1] += math.Max(v, 0)
+		}
+		values := make([]string, 12)
+		for m := 0; m < 12; m++ {
+			sums[m] = perYear(m, sums[m])
+			values[m] = statFloat(sums[m], 0)
+			annual[dd.name] += sums[m]
+		}
+		writeSTATRow(out, dd.name, values)
+	}
+	out.WriteString("\n")
+	out.WriteString(fmt.Sprintf("   - %5.0f annual heating degree-days (10°C baseline)\n", annual["HDD base 10C"]))
+	out.WriteString(fmt.Sprintf("   - %5.0f annual heating degree-days (18°C baseline)\n", annual["HDD base 18C"]))
+	out.WriteString(fmt.Sprintf("   - %5.0f annual cooling degree-days (10°C baseline)\n", annual["CDD base 10C"]))
+	out.WriteString(fmt.Sprintf("   - %5.0f annual cooling degree-days (18°C baseline)\n", annual["CDD base 18C"]))
+	out.WriteString("\n")
+
+	//
+	// ケッペンの気候区分
+	//
+	var TMP [12]float64
+	var P [12]float64
+	for m := 0; m < 12; m++ {
+		var sum float64
+		for _, i := range monthIndex[m] {
+			sum += msm.TMP[i]
+		}
+		TMP[m] = sum / float64(len(monthIndex[m]))
+		P[m] = APCP[m]
+	}
+	koppen := KoppenClimate(TMP, P)
+	out.WriteString(fmt.Sprintf(" - Climate type \"%s\" (Köppen classification)*\n", koppen))
+	if desc := KoppenDescription(koppen); desc != "" {
+		out.WriteString(fmt.Sprintf(" - %s\n", desc))
+	}
+	out.WriteString("   * Classified by the Peel et al. (2007) criteria from the monthly mean dry-bulb temperature and precipitation of this data set.\n\n")
+
+	//
+	// 代表週・極端週
+	//
+	out.WriteString(" - Typical/Extreme Period Determination\n\n")
+	// 複数年のデータでは年ごとに選び、年を付けて出力
+	periods := msm.TypicalExtremePeriodsByYear()
+	periodDay := monthDay
+	if len(msm.years()) > 1 {
+		periodDay = func(t time.Time) string {
+			return fmt.Sprintf("%d %s", t.Year(), monthDay(t))
+		}
+	}
+	for _, season := range Seasons {
+		ms := season.Months
+		out.WriteString(fmt.Sprintf(" - %s is %s:%s\n", season.Name, monthName(ms[0]), monthName(ms[len(ms)-1])))
+		for _, p := range periods {
+			if p.Season != season.Name {
+				continue
+			}
+			label := "Typical Week Period selected"
+			if p.Kind == "Extreme" {
+				if season.Name == "Summer" {
+					label = "Extreme Hot Week Period selected"
+				} else {
+					label = "Extreme Cold Week Period selected"
+				}
+			}
+			out.WriteString(fmt.Sprintf("   %s: %s:%s, Weekly Mean Temp=%6.2f°C, Deviation=|%6.3f|°C\n",
+				label, periodDay(p.Start), periodDay(p.End), p.Value, math.Abs(p.Deviation)))
+		}
+		out.WriteString("\n")
+	}
+}
+
+// 月別の最大値・最小値とその日時、平均値を出力します。
+func writeSTATMonthlyMaxMin(out *bytes.Buffer, msm *MsmTarget, list []float64, monthIndex [][]int, months []string, prec int) {
+	max := make([]string, 12)
+	maxAt := make([]string, 12)
+	min := make([]string, 12)
+	minAt := make([]string, 12)
+	for m := 0; m < 12; m++ {
+		if len(monthIndex[m]) == 0 {
+			continue
+		}
+		imax, imin := monthIndex[m][0], monthIndex[m][0]
+		for _, i := range monthIndex[m] {
+			if list[i] > list[imax] {
+				imax = i
+			}
+			if list[i] < list[imin] {
+				imin = i
+			}
+		}
+		max[m] = statFloat(list[imax], prec)
+		maxAt[m] = dayHour(msm.date[imax])
+		min[m] = statFloat(list[imin], prec)
+		minAt[m] = dayHour(msm.date[imin])
+	}
+	writeSTATRow(out, "", months)
+	writeSTATRow(out, "Maximum", max)
+	writeSTATRow(out, "Day:Hour", maxAt)
+	writeSTATRow(out, "Minimum", min)
+	writeSTATRow(out, "Day:Hour", minAt)
+	writeSTATRow(out, "Daily Avg", monthlyMeans(list, monthIndex, prec))
+}
+
+// 月別の平均値を文字列で返します。
+func monthlyMeans(list []float64, monthIndex [][]int, prec int) []string {
+	values := make([]string, 12)
+	for m := 0; m < 12; m++ {
+		var sum float64
+		for _, i := range monthIndex[m] {
+			sum += list[i]
+		}
+		values[m] = statFloat(sum/float64(len(monthIndex[m])), prec)
+	}
+	return values
+}
+
+// 日別の積算値
+type dailySum struct {
+	dates  []time.Time
+	values []float64
+}
+
+// 添字 index の時刻の値 list の日別の積算値を求めます。
+func dailySums(msm *MsmTarget, list []float64, index []int) dailySum {
+	ds := dailySum{}
+	msm.eachDay(index, func(date time.Time, dayIndex []int) {
+		var sum float64
+		for _, i := range dayIndex {
+			sum += list[i]
+		}
+		ds.dates = append(ds.dates, date)
+		ds.values = append(ds.values, sum)
+	})
+	return ds
+}
+
+// STAT形式の1行(見出しとタブ区切りの値)を出力します。
+func writeSTATRow(out *bytes.Buffer, label string, values []string) {
+	out.WriteString(" ")
+	out.WriteString(label)
+	for _, v := range values {
+		out.WriteString("\t")
+		out.WriteString(v)
+	}
+	out.WriteString("\n")
+}
+
+// 数値を小数点以下 prec 桁の文字列で返します。NaN は空文字列とします。
+func statFloat(v float64, prec int) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return ""
+	}
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	if z, _ := strconv.ParseFloat(s, 64); z == 0 {
+		// -0.0 を 0.0 とする
+		s = strings.TrimPrefix(s, "-")
+	}
+	return s
+}
+
+// 日平均気温
+type dailyTemperature struct {
+	date time.Time
+	TMP  float64 //日平均気温 [℃]
+}
+
+// 気温 TMP から日平均気温を求めます。
+func (msm *MsmTarget) dailyTemperatures() []dailyTemperature {
+	days := []dailyTemperature{}
+	msm.eachDay(nil, func(date time.Time, index []int) {
+		days = append(days, dailyTemperature{date: date, TMP: meanAt(msm.TMP, index)})
+	})
+	return days
+}
+
+// 日時 t を STAT形式の 日:時 (時は1から24) で返します。
+func dayHour(t time.Time) string {
+	return fmt.Sprintf("%2d:%2d", t.Day(), t.Hour()+1)
+}
+
+// 日付 t を STAT形式の 月 日 で返します。
+func monthDay(t time.Time) string {
+	return fmt.Sprintf("%s %2d", monthName(int(t.Month())), t.Day())
+}
+
+// 月 m (1-12) の英語の略称を返します。
+func monthName(m int) string {
+	return time.Month(m).String()[:3]
+}
+
+// 緯度・経度 v を {N 35° 14'} の形式で返します。
+func degreeMinute(v float64, pos string, neg string) string {
+	hemi := pos
+	if v < 0 {
+		hemi = neg
+	}
+	minutes := int(math.Round(math.Abs(v) * 60))
+	return fmt.Sprintf("{%s %3d° %2d'}", hemi, minutes/60, minutes%60)
+}
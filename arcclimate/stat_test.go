@@ -0,0 +1,59 @@
+package arcclimate
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/udawtr/arcclimate-go/arcclimate/psychrometrics"
+)
+
+// ケッペンの気候区分
+func Test_KoppenClimate(t *testing.T) {
+	// 東京 (1991-2020年の平年値)
+	TMP := [12]float64{5.4, 6.1, 9.4, 14.3, 18.8, 21.9, 25.7, 26.9, 23.3, 18.0, 12.5, 7.7}
+	APCP := [12]float64{59.7, 56.5, 116.0, 133.7, 139.7, 167.8, 156.2, 154.7, 224.9, 234.8, 96.3, 57.9}
+	assert.Equal(t, "Cfa", KoppenClimate(TMP, APCP))
+
+	// 札幌 (1991-2020年の平年値)
+	TMP = [12]float64{-3.2, -2.7, 1.1, 7.3, 13.0, 17.0, 21.1, 22.3, 18.6, 12.1, 5.2, -0.9}
+	APCP = [12]float64{108.4, 91.9, 77.6, 54.6, 55.5, 60.4, 90.7, 126.8, 142.2, 109.9, 113.8, 114.5}
+	assert.Equal(t, "Dfa", KoppenClimate(TMP, APCP))
+
+	// 乾燥帯
+	APCP = [12]float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}
+	assert.Equal(t, "BWk", KoppenClimate(TMP, APCP))
+}
+
+// STAT形式の出力
+func Test_ToSTAT(t *testing.T) {
+	msm := designTestTarget(2011, 2012)
+	for range msm.date {
+		msm.RH = append(msm.RH, 60)
+		msm.DT = append(msm.DT, math.NaN()) // 露点温度は DT ではなく Pw から求める
+		msm.APCP01 = append(msm.APCP01, 0.2)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	msm.ToSTAT(buf, 35.0, 139.0)
+	s := buf.String()
+
+	assert.Contains(t, s, "{N  35°  0'} {E 139°  0'}")
+	assert.Contains(t, s, " - Monthly Statistics for Dry Bulb temperatures °C\n")
+	assert.Contains(t, s, " Total\t149\t")
+	DP := statFloat(psychrometrics.DewPoint(msm.Pw[0]), 1)
+	assert.Contains(t, s, " - Monthly Statistics for Dew Point temperatures °C\n \tJan\tFeb\tMar\tApr\tMay\tJun\tJul\tAug\tSep\tOct\tNov\tDec\n Maximum\t"+DP+"\t")
+	assert.Contains(t, s, " - Climate type \"Cfa\" (Köppen classification)*\n")
+	assert.Contains(t, s, " - Summer is Jun:Aug\n")
+	// 複数年のため年ごとに年を付けて出力
+	assert.Contains(t, s, "Extreme Hot Week Period selected: 2011 Jul ")
+	assert.Contains(t, s, "Extreme Cold Week Period selected: 2012 Jan ")
+
+	// 同じ入力に対して同じ出力
+	buf2 := bytes.NewBuffer(nil)
+	msm.ToSTAT(buf2, 35.0, 139.0)
+	assert.Equal(t, s, buf2.String())
+	assert.False(t, strings.Contains(s, "NaN"))
+}
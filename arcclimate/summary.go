@@ -0,0 +1,204 @@
+package arcclimate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+//--------------------------------------
+// 月別・年別の気象概況
+//--------------------------------------
+
+// 月別・年別の気象概況の1行
+// Month が 0 の行は年間の値です。
+type ClimateSummaryRow struct {
+	Year     int       `json:"year"`
+	Month    int       `json:"month"`      //月 (年間の場合は0)
+	TMPMean  float64   `json:"tmp_mean"`   //平均気温 [℃]
+	TMPMax   float64   `json:"tmp_max"`    //最高気温 [℃]
+	TMPMin   float64   `json:"tmp_min"`    //最低気温 [℃]
+	RHMean   float64   `json:"rh_mean"`    //平均相対湿度 [%]
+	GH       JSONFloat `json:"gh"`         //水平面全天日射量の積算値 [MJ/m2]
+	DN       JSONFloat `json:"dn"`         //法線面直達日射量の積算値 [MJ/m2]
+	SH       JSONFloat `json:"sh"`         //水平面天空日射量の積算値 [MJ/m2]
+	APCP     float64   `json:"apcp"`       //降水量の積算値 [mm]
+	WSpdMean float64   `json:"w_spd_mean"` //平均風速 [m/s]
+	WDir     string    `json:"w_dir"`      //最多風向 (16方位)
+	NR       float64   `json:"nr"`         //夜間放射量の積算値 [MJ/m2]
+}
+
+// 月別・年別の気象概況
+type ClimateSummary struct {
+	Rows []ClimateSummaryRow `json:"rows"` //年ごとに1月から12月, 年間の順
+}
+
+// 気温 TMP, 相対湿度 RH, 日射量 DSWRF と直散分離結果, 降水量 APCP01, 風速 W_spd, 風向 W_dir, 夜間放射量 NR から
+// 年ごとに月別と年間の気象概況を求めます。
+// 標準年(EA)の場合は1970年の1年分の概況となります。
+// 日射量がない場合は日射量の項目を NaN とし、最多風向は風速0の時刻を除いて求めます。
+func (msm *MsmTarget) Summarize() *ClimateSummary {
+	summary := &ClimateSummary{Rows: []ClimateSummaryRow{}}
+
+	msm.eachMonthAndYear(func(index []int, month int) {
+		summary.Rows = append(summary.Rows, msm.summarizeRow(index, month))
+	})
+
+	return summary
+}
+
+// 時刻 index の気象概況を求めます。
+func (msm *MsmTarget) summarizeRow(index []int, month int) ClimateSummaryRow {
+	row := ClimateSummaryRow{
+		Year:   msm.date[index[0]].Year(),
+		Month:  month,
+		TMPMax: math.Inf(-1),
+		TMPMin: math.Inf(1),
+		GH:     JSONFloat(math.NaN()),
+		DN:     JSONFloat(math.NaN()),
+		SH:     JSONFloat(math.NaN()),
+	}
+
+	var TMP_sum, RH_sum, WS_sum float64
+	var dirCount [16]int
+	for _, i := range index {
+		TMP_sum += msm.TMP[i]
+		row.TMPMax = math.Max(row.TMPMax, msm.TMP[i])
+		row.TMPMin = math.Min(row.TMPMin, msm.TMP[i])
+		RH_sum += msm.RH[i]
+		row.APCP += msm.APCP01[i]
+		WS_sum += msm.W_spd[i]
+		if msm.W_spd[i] > 0 {
+			dirCount[int(math.Round(msm.W_dir[i]/22.5))%16]++
+		}
+		row.NR += msm.NR[i]
+	}
+	n := float64(len(index))
+	row.TMPMean = TMP_sum / n
+	row.RHMean = RH_sum / n
+	row.WSpdMean = WS_sum / n
+
+	k := 0
+	for j := range dirCount {
+		if dirCount[j] > dirCount[k] {
+			k = j
+		}
+	}
+	if dirCount[k] > 0 {
+		row.WDir = windDirectionNames[k]
+	}
+
+	DSWRF := msm.dswrf()
+	SR := msm.sr()
+	if DSWRF != nil && SR != nil {
+		var GH, DN, SH float64
+		for _, i := range index {
+			GH += DSWRF[i]
+			DN += SR[i].DN
+			SH += SR[i].SH
+		}
+		row.GH, row.DN, row.SH = JSONFloat(GH), JSONFloat(DN), JSONFloat(SH)
+	}
+
+	return row
+}
+
+// 気象概況の列名
+var summaryColumns = []string{
+	"year", "month", "tmp_mean", "tmp_max", "tmp_min", "rh_mean",
+	"gh", "dn", "sh", "apcp", "w_spd_mean", "w_dir", "nr",
+}
+
+// 気象概況の値を文字列で返します。年間の行の月は "annual" とします。
+func (row ClimateSummaryRow) values() []string {
+	f := func(v float64, prec int) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		return strconv.FormatFloat(v, 'f', prec, 64)
+	}
+	return []string{
+		strconv.Itoa(row.Year),
+		monthOrAnnual(row.Month),
+		f(row.TMPMean, 1),
+		f(row.TMPMax, 1),
+		f(row.TMPMin, 1),
+		f(row.RHMean, 0),
+		f(float64(row.GH), 1),
+		f(float64(row.DN), 1),
+		f(float64(row.SH), 1),
+		f(row.APCP, 1),
+		f(row.WSpdMean, 1),
+		row.WDir,
+		f(row.NR, 1),
+	}
+}
+
+// 気象概況をCSV形式で出力します。
+func (s *ClimateSummary) ToCSV(buf *bytes.Buffer) {
+	writeRow := func(values []string) {
+		for k, v := range values {
+			if k > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString(v)
+		}
+		buf.WriteString("\n")
+	}
+
+	writeRow(summaryColumns)
+	for _, row := range s.Rows {
+		writeRow(row.values())
+	}
+}
+
+// 気象概況をJSON形式で出力します。
+func (s *ClimateSummary) ToJSON(buf *bytes.Buffer) {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	buf.Write(b)
+	buf.WriteString("\n")
+}
+
+// 気象概況を年ごとのMarkdownの表で出力します。
+func (s *ClimateSummary) ToMarkdown(buf *bytes.Buffer) {
+	header := []string{
+		"Month", "Mean T [°C]", "Max T [°C]", "Min T [°C]", "RH [%]",
+		"Global [MJ/m²]", "Direct normal [MJ/m²]", "Diffuse [MJ/m²]", "Precipitation [mm]",
+		"Wind [m/s]", "Prevailing direction", "Nocturnal radiation [MJ/m²]",
+	}
+	writeRow := func(values []string) {
+		buf.WriteString("|")
+		for _, v := range values {
+			buf.WriteString(" ")
+			buf.WriteString(v)
+			buf.WriteString(" |")
+		}
+		buf.WriteString("\n")
+	}
+
+	for k, row := range s.Rows {
+		if k == 0 || s.Rows[k-1].Month == 0 {
+			if k > 0 {
+				buf.WriteString("\n")
+			}
+			buf.WriteString(fmt.Sprintf("## %d\n\n", row.Year))
+			writeRow(header)
+			sep := make([]string, len(header))
+			sep[0] = "---"
+			for j := 1; j < len(sep); j++ {
+				sep[j] = "---:"
+			}
+			writeRow(sep)
+		}
+		values := row.values()[1:]
+		if row.Month == 0 {
+			values[0] = "Year"
+		}
+		writeRow(values)
+	}
+}
@@ -0,0 +1,74 @@
+package arcclimate
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 気象概況の試験用データ (2年分)
+func summaryTestTarget() *MsmTarget {
+	msm := designTestTarget(2011, 2012)
+	for range msm.date {
+		msm.RH = append(msm.RH, 60.0)
+		msm.APCP01 = append(msm.APCP01, 0.1)
+		msm.NR = append(msm.NR, 0.2)
+	}
+	return msm
+}
+
+// 月別・年別の気象概況
+func Test_Summarize(t *testing.T) {
+	s := summaryTestTarget().Summarize()
+
+	// 年ごとに12か月と年間
+	assert.Equal(t, 26, len(s.Rows))
+	assert.Equal(t, 2011, s.Rows[0].Year)
+	assert.Equal(t, 1, s.Rows[0].Month)
+	assert.Equal(t, 0, s.Rows[12].Month)
+	assert.Equal(t, 2012, s.Rows[13].Year)
+
+	jan := s.Rows[0]
+	assert.InDelta(t, 31*24*0.1, jan.APCP, 1e-9)
+	assert.InDelta(t, 31*24*0.2, jan.NR, 1e-9)
+	assert.InDelta(t, 60.0, jan.RHMean, 1e-9)
+	assert.InDelta(t, 3.0, jan.WSpdMean, 1e-9)
+	assert.Equal(t, "North", jan.WDir)
+	assert.True(t, math.IsNaN(float64(jan.GH)))
+
+	annual := s.Rows[12]
+	assert.InDelta(t, 15.0, annual.TMPMean, 0.1)
+	assert.InDelta(t, 29.0, annual.TMPMax, 0.1)
+	assert.InDelta(t, 1.0, annual.TMPMin, 0.1)
+	assert.InDelta(t, 365*24*0.1, annual.APCP, 1e-6)
+	assert.True(t, s.Rows[6].TMPMean > s.Rows[0].TMPMean)
+}
+
+// CSV, JSON, Markdown 出力
+func Test_ClimateSummaryOutput(t *testing.T) {
+	s := summaryTestTarget().Summarize()
+
+	buf := bytes.NewBuffer(nil)
+	s.ToCSV(buf)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, "year,month,tmp_mean,tmp_max,tmp_min,rh_mean,gh,dn,sh,apcp,w_spd_mean,w_dir,nr", lines[0])
+	assert.Equal(t, 27, len(lines))
+	assert.True(t, strings.HasPrefix(lines[13], "2011,annual,"))
+	assert.Contains(t, lines[1], ",,,")
+
+	buf.Reset()
+	s.ToJSON(buf)
+	assert.Contains(t, buf.String(), `"gh": null`)
+	assert.Contains(t, buf.String(), `"w_dir": "North"`)
+
+	buf.Reset()
+	s.ToMarkdown(buf)
+	md := buf.String()
+	assert.Contains(t, md, "## 2011\n\n| Month |")
+	assert.Contains(t, md, "## 2012\n\n")
+	assert.Contains(t, md, "| Year | 15.0 |")
+	assert.Equal(t, 2, strings.Count(md, "| --- |"))
+}
@@ -0,0 +1,93 @@
+package arcclimate
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+//--------------------------------------
+// 給水温度
+//--------------------------------------
+
+// 地域区分ごとの日平均給水温度の推定式の係数 (a_wtr, b_wtr) (1地域から8地域の順)
+// 省エネ基準の住宅の給湯設備の計算方法による値です。
+var SupplyWaterCoefficients = [8][2]float64{
+	{0.6639, 3.466},
+	{0.6639, 3.466},
+	{0.6054, 4.515},
+	{0.6054, 4.515},
+	{0.8660, 1.665},
+	{0.8516, 2.473},
+	{0.9223, 2.097},
+	{0.6921, 7.167},
+}
+
+// 給水温度の推定に用いる日平均外気温度の期間平均の日数 [日]
+const supplyWaterPeriod = 10
+
+// 日平均給水温度の下限 [℃]
+const supplyWaterMin = 0.5
+
+// 日別の給水温度に関するデータ
+type SupplyWaterDay struct {
+	Date time.Time //日付
+	TEX  float64   //日平均外気温度 (単位:℃)
+	TPRD float64   //前日までの10日間の日平均外気温度の期間平均 (単位:℃)
+	WTR  float64   //日平均給水温度 (単位:℃)
+}
+
+// 地域区分 region (1-8) の係数を用いて、気温 TMP から日平均給水温度を推定します。
+// 日平均給水温度は a_wtr θprd + b_wtr (下限0.5℃) とし、θprd は前日までの10日間の日平均外気温度の平均です。
+// 期間の始めの10日間は、期間末の日平均外気温度を前年の値として循環させて求めます。
+// 時刻別の給水温度 WTR には当日の日平均給水温度を設定します。
+func (msm *MsmTarget) CalcSupplyWaterTemperature(region int) ([]SupplyWaterDay, error) {
+	if region < 1 || region > len(SupplyWaterCoefficients) {
+		return nil, fmt.Errorf("invalid region %d", region)
+	}
+	a := SupplyWaterCoefficients[region-1][0]
+	b := SupplyWaterCoefficients[region-1][1]
+
+	// 日平均外気温度
+	days := []SupplyWaterDay{}
+	dayIndex := make([]int, len(msm.date))
+	msm.eachDay(nil, func(date time.Time, index []int) {
+		for _, i := range index {
+			dayIndex[i] = len(days)
+		}
+		days = append(days, SupplyWaterDay{Date: date, TEX: meanAt(msm.TMP, index)})
+	})
+
+	// 期間平均外気温度と給水温度
+	n := len(days)
+	for d := 0; d < n; d++ {
+		var s float64
+		for k := 1; k <= supplyWaterPeriod; k++ {
+			s += days[((d-k)%n+n)%n].TEX
+		}
+		days[d].TPRD = s / supplyWaterPeriod
+		days[d].WTR = math.Max(a*days[d].TPRD+b, supplyWaterMin)
+	}
+
+	msm.WTR = make([]float64, len(msm.date))
+	for i := 0; i < len(msm.date); i++ {
+		msm.WTR[i] = days[dayIndex[i]].WTR
+	}
+
+	return days, nil
+}
+
+// 日別の給水温度をCSV形式で出力します。
+func SupplyWaterToCSV(buf *bytes.Buffer, days []SupplyWaterDay) {
+	buf.WriteString("date,TEX,TPRD,WTR\n")
+	for _, day := range days {
+		buf.WriteString(day.Date.Format("2006-01-02"))
+		for _, v := range []float64{day.TEX, day.TPRD, day.WTR} {
+			buf.WriteString(",")
+			buf.WriteString(strconv.FormatFloat(v, 'f', 2, 64))
+		}
+		buf.WriteString("\n")
+	}
+}
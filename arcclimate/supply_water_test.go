@@ -0,0 +1,51 @@
+package arcclimate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 給水温度
+func Test_CalcSupplyWaterTemperature(t *testing.T) {
+	msm := designTestTarget(2011, 2011)
+	days, err := msm.CalcSupplyWaterTemperature(6)
+	assert.Nil(t, err)
+	assert.Equal(t, 365, len(days))
+
+	// 前日までの10日間の平均
+	var s float64
+	for d := 20; d < 30; d++ {
+		s += days[d].TEX
+	}
+	assert.InDelta(t, s/10, days[30].TPRD, 1e-9)
+	assert.InDelta(t, 0.8516*days[30].TPRD+2.473, days[30].WTR, 1e-9)
+
+	// 期間の始めは期間末から循環
+	s = 0
+	for d := 356; d < 365; d++ {
+		s += days[d].TEX
+	}
+	s += days[0].TEX
+	assert.InDelta(t, s/10, days[1].TPRD, 1e-9)
+
+	// 時刻別の値は当日の値
+	assert.Equal(t, days[30].WTR, msm.WTR[30*24+5])
+
+	// 下限
+	cold := designTestTarget(2011, 2011)
+	for i := range cold.TMP {
+		cold.TMP[i] -= 20
+	}
+	days, _ = cold.CalcSupplyWaterTemperature(1)
+	assert.Equal(t, 0.5, days[20].WTR)
+
+	buf := bytes.NewBuffer(nil)
+	SupplyWaterToCSV(buf, days)
+	assert.True(t, strings.HasPrefix(buf.String(), "date,TEX,TPRD,WTR\n2011-01-01,"))
+
+	_, err = msm.CalcSupplyWaterTemperature(9)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,143 @@
+package arcclimate
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+)
+
+//--------------------------------------
+// 暑熱・温熱環境指標の計算
+//--------------------------------------
+
+// 暑熱・温熱環境指標に関するデータ
+type ThermalIndex struct {
+	WBGT float64 //暑さ指数 (単位:℃)
+	HI   float64 //暑熱指数 Heat Index (単位:℃)
+	DI   float64 //不快指数 (-)
+	AT   float64 //体感温度 Apparent Temperature (単位:℃)
+}
+
+// 暑さ指数の危険度の区分の閾値 [℃]
+// 環境省「熱中症環境保健マニュアル」の日常生活に関する指針による
+var wbgtBIN = []float64{21.0, 25.0, 28.0, 31.0}
+
+// 暑さ指数の危険度の区分名
+var wbgtLevels = []string{"safe", "caution", "warning", "severe_warning", "danger"}
+
+// 気温 TMP, 相対湿度 RH, 水蒸気分圧 Pw, 日射量, 風速 W_spd から暑熱・温熱環境指標を計算します。
+// 日射量は dswrf() で選択される日射量を用います。
+func (msm *MsmTarget) CalcThermalIndices() {
+	DSWRF := msm.dswrf()
+
+	msm.TI = make([]ThermalIndex, len(msm.date))
+
+	for i := 0; i < len(msm.date); i++ {
+		TMP := msm.TMP[i]
+		RH := msm.RH[i]
+
+		// 日射量 [kW/m2]
+		SR := MJ_to_W(DSWRF[i]) / 1000
+
+		msm.TI[i] = ThermalIndex{
+			WBGT: wbgtRegression(TMP, RH, SR, msm.W_spd[i]),
+			HI:   heatIndex(TMP, RH),
+			DI:   discomfortIndex(TMP, RH),
+			AT:   apparentTemperature(TMP, msm.Pw[i], msm.W_spd[i]),
+		}
+	}
+}
+
+// 気温 Ta [℃], 相対湿度 RH [%], 全天日射量 SR [kW/m2], 風速 WS [m/s] から暑さ指数 [℃] を求めます。
+// 環境省の暑さ指数の推定式(小野ら 2014)を用いています。日射量が欠測の場合は NaN を返します。
+//
+// 参照)
+//
+//	小野雅司, 登内道彦. 通常観測気象要素を用いたWBGT(湿球黒球温度)の推定.
+//	日本生気象学会雑誌 2014; 50(4): 147-157.
+func wbgtRegression(Ta float64, RH float64, SR float64, WS float64) float64 {
+	return 0.735*Ta + 0.0374*RH + 0.00292*Ta*RH + 7.619*SR - 4.557*SR*SR - 0.0572*WS - 4.064
+}
+
+// 気温 Ta [℃] と 相対湿度 RH [%] から米国気象局の Heat Index [℃] を求めます。
+// Steadman の簡易式の値が 80°F 以上の場合は Rothfusz の回帰式と補正を用います。
+func heatIndex(Ta float64, RH float64) float64 {
+	T := Ta*9/5 + 32 // °F
+
+	HI := 0.5 * (T + 61.0 + (T-68.0)*1.2 + RH*0.094)
+
+	if (HI+T)/2 >= 80.0 {
+		HI = -42.379 + 2.04901523*T + 10.14333127*RH -
+			0.22475541*T*RH - 0.00683783*T*T - 0.05481717*RH*RH +
+			0.00122874*T*T*RH + 0.00085282*T*RH*RH - 0.00000199*T*T*RH*RH
+
+		if RH < 13.0 && 80.0 <= T && T <= 112.0 {
+			HI -= (13.0 - RH) / 4 * math.Sqrt((17.0-math.Abs(T-95.0))/17)
+		} else if RH > 85.0 && 80.0 <= T && T <= 87.0 {
+			HI += (RH - 85.0) / 10 * (87.0 - T) / 5
+		}
+	} else {
+		HI = (HI + T) / 2
+	}
+
+	return (HI - 32) * 5 / 9
+}
+
+// 気温 Ta [℃] と 相対湿度 RH [%] から不快指数 [-] を求めます。
+func discomfortIndex(Ta float64, RH float64) float64 {
+	return 0.81*Ta + 0.01*RH*(0.99*Ta-14.3) + 46.3
+}
+
+// 気温 Ta [℃], 水蒸気分圧 e [hPa], 風速 WS [m/s] から体感温度 [℃] を求めます。
+// Steadman (1994) の日射を考慮しない式を用いています。
+func apparentTemperature(Ta float64, e float64, WS float64) float64 {
+	return Ta + 0.33*e - 0.70*WS - 4.00
+}
+
+// 年別に暑さ指数の危険度の区分(wbgtLevels)ごとの時間数を集計します。
+// CalcThermalIndices で暑さ指数が計算済みである必要があります。欠測の時刻は集計しません。
+func (msm *MsmTarget) WBGTRiskHours() map[int][]int {
+	hours := map[int][]int{}
+
+	for i := 0; i < len(msm.date); i++ {
+		WBGT := msm.TI[i].WBGT
+		if math.IsNaN(WBGT) {
+			continue
+		}
+
+		year := msm.date[i].Year()
+		if _, ok := hours[year]; !ok {
+			hours[year] = make([]int, len(wbgtLevels))
+		}
+		hours[year][IndexOf(WBGT, wbgtBIN)]++
+	}
+
+	return hours
+}
+
+// 年別の暑さ指数の危険度の区分ごとの時間数をCSV形式で出力します。
+func (msm *MsmTarget) WBGTRiskHoursToCSV(buf *bytes.Buffer) {
+	hours := msm.WBGTRiskHours()
+
+	years := make([]int, 0, len(hours))
+	for year := range hours {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+
+	buf.WriteString("year")
+	for _, level := range wbgtLevels {
+		buf.WriteString(",")
+		buf.WriteString(level)
+	}
+	buf.WriteString("\n")
+
+	for _, year := range years {
+		buf.WriteString(fmt.Sprintf("%d", year))
+		for _, h := range hours[year] {
+			buf.WriteString(fmt.Sprintf(",%d", h))
+		}
+		buf.WriteString("\n")
+	}
+}
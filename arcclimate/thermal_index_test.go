@@ -0,0 +1,43 @@
+package arcclimate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 暑熱・温熱環境指標のテスト
+func Test_thermalIndices(t *testing.T) {
+	// 気温33℃, 相対湿度60%, 日射量0.8kW/m2, 風速2m/s の暑さ指数は概ね31℃(危険)
+	WBGT := wbgtRegression(33.0, 60.0, 0.8, 2.0)
+	assert.InDelta(t, 31.0, WBGT, 1.0)
+
+	// Heat Index: 90°F, 70% は 106°F (NWS の早見表)
+	assert.InDelta(t, (106.0-32)*5/9, heatIndex((90.0-32)*5/9, 70.0), 0.5)
+
+	// Heat Index: 低温時は気温に近い値
+	assert.InDelta(t, 10.0, heatIndex(10.0, 50.0), 1.5)
+
+	// 不快指数: 30℃, 80% は 82.9
+	assert.InDelta(t, 82.92, discomfortIndex(30.0, 80.0), 1.0e-9)
+
+	// 体感温度
+	assert.InDelta(t, 25.0+0.33*20.0-0.70*3.0-4.0, apparentTemperature(25.0, 20.0, 3.0), 1.0e-9)
+}
+
+// 暑さ指数の危険度の区分ごとの時間数のテスト
+func Test_WBGTRiskHours(t *testing.T) {
+	msm := &MsmTarget{
+		date: []time.Time{
+			time.Date(2011, 8, 1, 12, 0, 0, 0, time.UTC),
+			time.Date(2011, 8, 1, 13, 0, 0, 0, time.UTC),
+			time.Date(2012, 8, 1, 12, 0, 0, 0, time.UTC),
+		},
+		TI: []ThermalIndex{{WBGT: 20.0}, {WBGT: 31.0}, {WBGT: 27.9}},
+	}
+
+	hours := msm.WBGTRiskHours()
+	assert.Equal(t, []int{1, 0, 0, 0, 1}, hours[2011])
+	assert.Equal(t, []int{0, 0, 1, 0, 0}, hours[2012])
+}
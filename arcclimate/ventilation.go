@@ -0,0 +1,162 @@
+package arcclimate
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+//--------------------------------------
+// 自然換気・夜間換気の利用可能性
+//--------------------------------------
+
+// 自然換気・夜間換気の判定条件
+type VentilationConfig struct {
+	TMPMin     float64 `json:"tmp_min"`     //自然換気に適する気温の下限 [℃]
+	TMPMax     float64 `json:"tmp_max"`     //自然換気に適する気温の上限 [℃]
+	DTMax      float64 `json:"dt_max"`      //自然換気に適する露点温度の上限 [℃]
+	RainMax    float64 `json:"rain_max"`    //自然換気に適する降水量の上限 [mm/h] (0の場合は降水なし)
+	WindMin    float64 `json:"wind_min"`    //自然換気に適する風速の下限 [m/s]
+	WindMax    float64 `json:"wind_max"`    //自然換気に適する風速の上限 [m/s]
+	Setpoint   float64 `json:"setpoint"`    //夜間換気の室内設定温度 [℃]
+	NightHours []int   `json:"night_hours"` //夜間換気の時刻
+	NightDelta float64 `json:"night_delta"` //夜間換気に必要な室内設定温度と気温の差の下限 [K]
+}
+
+// 既定の自然換気・夜間換気の判定条件
+var DefaultVentilationConfig = VentilationConfig{
+	TMPMin:     18,
+	TMPMax:     26,
+	DTMax:      17,
+	RainMax:    0,
+	WindMin:    0,
+	WindMax:    6,
+	Setpoint:   24,
+	NightHours: []int{0, 1, 2, 3, 4, 5, 6, 22, 23},
+	NightDelta: 3,
+}
+
+// 月別・年別の自然換気・夜間換気の利用可能性の1行
+// Month が 0 の行は年間の値です。
+type VentilationRow struct {
+	Year       int     `json:"year"`
+	Month      int     `json:"month"`       //月 (年間の場合は0)
+	Hours      int     `json:"hours"`       //対象の時間数 [h]
+	VentHours  int     `json:"vent_hours"`  //自然換気に適する時間数 [h]
+	VentRatio  float64 `json:"vent_ratio"`  //自然換気に適する時間の割合 [%]
+	Cold       int     `json:"cold"`        //気温が下限未満の時間数 [h]
+	Hot        int     `json:"hot"`         //気温が上限を超える時間数 [h]
+	Humid      int     `json:"humid"`       //露点温度が上限を超える時間数 [h]
+	Rain       int     `json:"rain"`        //降水量が上限を超える時間数 [h]
+	Wind       int     `json:"wind"`        //風速が範囲外の時間数 [h]
+	NightHours int     `json:"night_hours"` //夜間換気に適する時間数 [h]
+	NightDH    float64 `json:"night_dh"`    //夜間換気の冷却ポテンシャル(室内設定温度と気温の差の積算値) [K・h]
+}
+
+// 月別・年別の自然換気・夜間換気の利用可能性
+type Ventilation struct {
+	Config VentilationConfig `json:"config"` //判定条件
+	Rows   []VentilationRow  `json:"rows"`   //年ごとに1月から12月, 年間の順
+}
+
+// 気温 TMP, 露点温度 DT, 降水量 APCP01, 風速 W_spd から年ごとに月別と年間の自然換気・夜間換気の利用可能性を求めます。
+// 自然換気は気温・露点温度・降水量・風速のすべてが条件 cfg の範囲内の時間とし、条件ごとに範囲外の時間数も集計します。
+// 夜間換気は夜間の時刻に室内設定温度と気温の差が NightDelta 以上の時間とし、その差を積算した度時を冷却ポテンシャルとします。
+func (msm *MsmTarget) CalcVentilation(cfg VentilationConfig) *Ventilation {
+	v := &Ventilation{Config: cfg, Rows: []VentilationRow{}}
+
+	night := make([]bool, 24)
+	for _, h := range cfg.NightHours {
+		night[h] = true
+	}
+
+	msm.eachMonthAndYear(func(index []int, month int) {
+		v.Rows = append(v.Rows, msm.ventilationRow(cfg, night, index, month))
+	})
+
+	return v
+}
+
+// 時刻 index の自然換気・夜間換気の利用可能性を集計します。
+func (msm *MsmTarget) ventilationRow(cfg VentilationConfig, night []bool, index []int, month int) VentilationRow {
+	row := VentilationRow{
+		Year:  msm.date[index[0]].Year(),
+		Month: month,
+		Hours: len(index),
+	}
+
+	for _, i := range index {
+		TMP := msm.TMP[i]
+
+		// 自然換気
+		ok := true
+		if TMP < cfg.TMPMin {
+			row.Cold++
+			ok = false
+		}
+		if TMP > cfg.TMPMax {
+			row.Hot++
+			ok = false
+		}
+		if msm.DT[i] > cfg.DTMax {
+			row.Humid++
+			ok = false
+		}
+		if msm.APCP01[i] > cfg.RainMax {
+			row.Rain++
+			ok = false
+		}
+		if msm.W_spd[i] < cfg.WindMin || msm.W_spd[i] > cfg.WindMax {
+			row.Wind++
+			ok = false
+		}
+		if ok {
+			row.VentHours++
+		}
+
+		// 夜間換気
+		if night[msm.date[i].Hour()] {
+			dT := cfg.Setpoint - TMP
+			if dT >= cfg.NightDelta {
+				row.NightHours++
+				row.NightDH += dT
+			}
+		}
+	}
+	row.VentRatio = float64(row.VentHours) / float64(row.Hours) * 100
+
+	return row
+}
+
+// 自然換気・夜間換気の利用可能性をCSV形式で出力します。年間の行の月は annual とします。
+func (v *Ventilation) ToCSV(buf *bytes.Buffer) {
+	buf.WriteString("year,month,hours,vent_hours,vent_ratio,cold,hot,humid,rain,wind,night_hours,night_dh\n")
+	for _, row := range v.Rows {
+		buf.WriteString(strings.Join([]string{
+			strconv.Itoa(row.Year),
+			monthOrAnnual(row.Month),
+			strconv.Itoa(row.Hours),
+			strconv.Itoa(row.VentHours),
+			strconv.FormatFloat(row.VentRatio, 'f', 1, 64),
+			strconv.Itoa(row.Cold),
+			strconv.Itoa(row.Hot),
+			strconv.Itoa(row.Humid),
+			strconv.Itoa(row.Rain),
+			strconv.Itoa(row.Wind),
+			strconv.Itoa(row.NightHours),
+			strconv.FormatFloat(row.NightDH, 'f', 1, 64),
+		}, ","))
+		buf.WriteString("\n")
+	}
+}
+
+// 自然換気・夜間換気の利用可能性をJSON形式で出力します。
+func (v *Ventilation) ToJSON(buf *bytes.Buffer) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	buf.Write(b)
+	buf.WriteString("\n")
+}
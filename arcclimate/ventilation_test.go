@@ -0,0 +1,95 @@
+package arcclimate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 自然換気の条件ごとの時間数
+func Test_CalcVentilation(t *testing.T) {
+	// 2011年5月1日の24時間
+	// 0-5時は 15℃, 6-9時は 20℃で露点 18℃, 10-11時は 22℃で降水, 12-13時は 22℃で風速 8m/s,
+	// 14-15時は 28℃, 16-23時は 22℃
+	msm := hourlyTestTarget(time.Date(2011, 5, 1, 0, 0, 0, 0, time.UTC), 24, func(i int) hourlyTestValue {
+		switch {
+		case i < 6:
+			return hourlyTestValue{TMP: 15, DT: 10, APCP01: 0, W_spd: 2}
+		case i < 10:
+			return hourlyTestValue{TMP: 20, DT: 18, APCP01: 0, W_spd: 2}
+		case i < 12:
+			return hourlyTestValue{TMP: 22, DT: 15, APCP01: 1.5, W_spd: 2}
+		case i < 14:
+			return hourlyTestValue{TMP: 22, DT: 15, APCP01: 0, W_spd: 8}
+		case i < 16:
+			return hourlyTestValue{TMP: 28, DT: 15, APCP01: 0, W_spd: 2}
+		}
+		return hourlyTestValue{TMP: 22, DT: 15, APCP01: 0, W_spd: 2}
+	})
+
+	v := msm.CalcVentilation(DefaultVentilationConfig)
+	assert.Len(t, v.Rows, 2)
+
+	row := v.Rows[0]
+	assert.Equal(t, 2011, row.Year)
+	assert.Equal(t, 5, row.Month)
+	assert.Equal(t, 24, row.Hours)
+	assert.Equal(t, 6, row.Cold)
+	assert.Equal(t, 4, row.Humid)
+	assert.Equal(t, 2, row.Rain)
+	assert.Equal(t, 2, row.Wind)
+	assert.Equal(t, 2, row.Hot)
+	assert.Equal(t, 8, row.VentHours)
+	assert.InDelta(t, 100.0/3, row.VentRatio, 1e-9)
+
+	// 夜間換気: 0-5時は 24-15=9K (6時は 24-20=4K), 22-23時は 24-22=2K で対象外
+	assert.Equal(t, 7, row.NightHours)
+	assert.InDelta(t, 6*9.0+4.0, row.NightDH, 1e-9)
+
+	// 年間の行
+	assert.Equal(t, 0, v.Rows[1].Month)
+	assert.Equal(t, row.VentHours, v.Rows[1].VentHours)
+	assert.Equal(t, row.NightDH, v.Rows[1].NightDH)
+
+	// 条件の変更: 降水 2mm/h まで許容し、夜間の時刻を 0-3時に限定
+	cfg := DefaultVentilationConfig
+	cfg.RainMax = 2
+	cfg.NightHours = []int{0, 1, 2, 3}
+	v = msm.CalcVentilation(cfg)
+	assert.Equal(t, 0, v.Rows[0].Rain)
+	assert.Equal(t, 10, v.Rows[0].VentHours)
+	assert.Equal(t, 4, v.Rows[0].NightHours)
+	assert.InDelta(t, 36.0, v.Rows[0].NightDH, 1e-9)
+}
+
+// 年と月の区切り
+func Test_CalcVentilation_Years(t *testing.T) {
+	// 2011年12月31日から2日間
+	msm := hourlyTestTarget(time.Date(2011, 12, 31, 0, 0, 0, 0, time.UTC), 48, func(i int) hourlyTestValue {
+		return hourlyTestValue{TMP: 20, DT: 10, APCP01: 0, W_spd: 2}
+	})
+
+	v := msm.CalcVentilation(DefaultVentilationConfig)
+	assert.Len(t, v.Rows, 4)
+	assert.Equal(t, 2011, v.Rows[0].Year)
+	assert.Equal(t, 12, v.Rows[0].Month)
+	assert.Equal(t, 0, v.Rows[1].Month)
+	assert.Equal(t, 2012, v.Rows[2].Year)
+	assert.Equal(t, 1, v.Rows[2].Month)
+	assert.Equal(t, 24, v.Rows[3].VentHours)
+	assert.InDelta(t, 100.0, v.Rows[3].VentRatio, 1e-9)
+
+	buf := bytes.NewBuffer(nil)
+	v.ToCSV(buf)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, "year,month,hours,vent_hours,vent_ratio,cold,hot,humid,rain,wind,night_hours,night_dh", lines[0])
+	assert.Equal(t, "2011,annual,24,24,100.0,0,0,0,0,0,9,36.0", lines[2])
+
+	buf.Reset()
+	v.ToJSON(buf)
+	assert.Contains(t, buf.String(), `"night_hours": [`)
+	assert.Contains(t, buf.String(), `"vent_ratio": 100`)
+}
@@ -8,20 +8,36 @@ import (
 // 風速風向計算
 //--------------------------------------
 
-// ベクトル風速 UGRD, VGRD から16方位の風向風速 w_dir, w_spd を計算
+// 風向風速の計算方法
+const (
+	WindMode16         = "16"         //16方位に丸めた風向と風向の差による補正後の風速
+	WindModeContinuous = "continuous" //連続的な風向(0-360°)とベクトルの大きさの風速
+)
+
+// ベクトル風速 UGRD, VGRD から16方位の風向風速 W_dir16, W_spd16 を計算し、
+// 計算方法 WindMode に応じた風向風速 W_dir, W_spd を設定します。
+// WindMode が WindModeContinuous の場合は連続的な風向風速を、それ以外の場合は16方位の風向風速を W_dir, W_spd とします。
 func (msm *MsmTarget) WindVectorToDirAndSpeed() {
 	msm.W_spd = make([]float64, len(msm.date))
 	msm.W_dir = make([]float64, len(msm.date))
+	msm.W_spd16 = make([]float64, len(msm.date))
+	msm.W_dir16 = make([]float64, len(msm.date))
 
 	for i := 0; i < len(msm.date); i++ {
 		// 風向風速の計算
 		w_spd16, w_dir16 := Wind16(msm.UGRD[i], msm.VGRD[i])
 
 		// 風速(16方位)
-		msm.W_spd[i] = w_spd16
+		msm.W_spd16[i] = w_spd16
 
 		// 風向(16方位)
-		msm.W_dir[i] = w_dir16
+		msm.W_dir16[i] = w_dir16
+
+		if msm.WindMode == WindModeContinuous {
+			msm.W_spd[i], msm.W_dir[i] = WindContinuous(msm.UGRD[i], msm.VGRD[i])
+		} else {
+			msm.W_spd[i], msm.W_dir[i] = w_spd16, w_dir16
+		}
 	}
 }
 
@@ -44,6 +60,26 @@ func Wind16(UGRD float64, VGRD float64) (w_spd16 float64, w_dir16 float64) {
 	return w_spd16, w_dir16
 }
 
+// 16方位の風速と風向を返します。W_spd16, W_dir16 が未設定の場合は W_spd, W_dir を返します。
+func (msm *MsmTarget) wind16() ([]float64, []float64) {
+	if msm.W_spd16 != nil && msm.W_dir16 != nil {
+		return msm.W_spd16, msm.W_dir16
+	}
+	return msm.W_spd, msm.W_dir
+}
+
+// ベクトル風速 UGRD (東西のベクトル成分), VGRD (南北のベクトル成分) から
+// 丸めない風向 w_dir [°] (北=0, 0以上360未満) と ベクトルの大きさの風速 w_spd を計算する
+// 無風の場合の風向は0とする
+func WindContinuous(UGRD float64, VGRD float64) (w_spd float64, w_dir float64) {
+	w_spd = math.Sqrt(UGRD*UGRD + VGRD*VGRD)
+	if w_spd == 0 {
+		return 0, 0
+	}
+	w_dir = math.Mod(radToDegree(math.Atan2(UGRD, VGRD)+math.Pi), 360)
+	return w_spd, w_dir
+}
+
 func radToDegree(rad float64) float64 {
 	return rad * 180.0 / math.Pi
 }
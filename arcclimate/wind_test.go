@@ -1,7 +1,12 @@
 package arcclimate
 
 import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -11,3 +16,67 @@ func Test_wind16(t *testing.T) {
 	assert.InDelta(t, 1.4141456, spd, 0.0001)
 	assert.Equal(t, 180.0+45.0, dir)
 }
+
+func Test_windContinuous(t *testing.T) {
+	// 南西の風 (北東に向かうベクトル)
+	spd, dir := WindContinuous(1.0, 1.0)
+	assert.InDelta(t, 1.4142136, spd, 0.0001)
+	assert.InDelta(t, 225.0, dir, 1e-9)
+
+	// 北風は0°
+	spd, dir = WindContinuous(0.0, -2.0)
+	assert.InDelta(t, 2.0, spd, 1e-9)
+	assert.InDelta(t, 0.0, dir, 1e-9)
+
+	// 16方位に丸めない
+	_, dir = WindContinuous(-1.0, -2.0)
+	assert.InDelta(t, radToDegree(math.Atan2(1, 2)), dir, 1e-9)
+
+	// 無風
+	spd, dir = WindContinuous(0.0, 0.0)
+	assert.Equal(t, 0.0, spd)
+	assert.Equal(t, 0.0, dir)
+}
+
+func Test_WindVectorToDirAndSpeed(t *testing.T) {
+	msm := &MsmTarget{
+		date: []time.Time{time.Date(2011, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2011, 1, 1, 1, 0, 0, 0, time.UTC)},
+		UGRD: []float64{-1.0, 0.0},
+		VGRD: []float64{-2.0, 0.0},
+	}
+
+	// 16方位 (既定)
+	msm.WindVectorToDirAndSpeed()
+	assert.Equal(t, msm.W_spd16, msm.W_spd)
+	assert.Equal(t, msm.W_dir16, msm.W_dir)
+	assert.Equal(t, 22.5, msm.W_dir[0])
+
+	// 連続的な風向風速
+	msm.WindMode = WindModeContinuous
+	msm.WindVectorToDirAndSpeed()
+	assert.InDelta(t, math.Sqrt(5), msm.W_spd[0], 1e-9)
+	assert.True(t, msm.W_spd[0] > msm.W_spd16[0])
+	assert.Equal(t, 22.5, msm.W_dir16[0])
+	assert.NotEqual(t, msm.W_dir16[0], msm.W_dir[0])
+	assert.Equal(t, 0.0, msm.W_spd[1])
+}
+
+// HASP形式の風向・風速は16方位の値を出力
+func Test_ToHAS_Wind(t *testing.T) {
+	msm := &MsmTarget{WindMode: WindModeContinuous}
+	start := time.Date(2011, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 365*24; i++ {
+		msm.date = append(msm.date, start.Add(time.Duration(i)*time.Hour))
+		msm.TMP = append(msm.TMP, 0)
+		msm.MR = append(msm.MR, 0)
+		msm.UGRD = append(msm.UGRD, -1)
+		msm.VGRD = append(msm.VGRD, -2)
+	}
+	msm.WindVectorToDirAndSpeed()
+
+	buf := bytes.NewBuffer(nil)
+	msm.ToHAS(buf)
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, strings.Repeat("  2", 24)+"11 1 116", lines[5])
+	assert.Equal(t, strings.Repeat(fmt.Sprintf("%3d", int(msm.W_spd16[0]*10)), 24)+"11 1 117", lines[6])
+}
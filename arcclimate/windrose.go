@@ -0,0 +1,359 @@
+package arcclimate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//--------------------------------------
+// 風配図・風の統計
+//--------------------------------------
+
+// 既定の静穏の風速 [m/s] (この値未満を静穏とします)
+const DefaultCalmSpeed = 0.3
+
+// 既定の風速階級の境界 [m/s]
+var DefaultWindSpeedClasses = []float64{1, 2, 3, 4, 6, 8}
+
+// 風配図の集計条件
+type WindRoseConfig struct {
+	Calm         float64   //静穏の風速 [m/s]
+	SpeedClasses []float64 //風速階級の境界 [m/s] (昇順)
+	Months       []int     //対象の月 (空の場合は全月)
+	Hours        []int     //対象の時刻 (空の場合は全時刻)
+}
+
+// 方位別の風の統計
+type WindSector struct {
+	Direction string    `json:"direction"`  //16方位
+	Angle     float64   `json:"angle"`      //方位角 [°] (北=0, 時計回り)
+	Frequency []float64 `json:"frequency"`  //風速階級ごとの出現率 [%] (静穏を含む全時間に対する割合)
+	Total     float64   `json:"total"`      //出現率 [%]
+	MeanSpeed JSONFloat `json:"mean_speed"` //平均風速 [m/s]
+	WeibullK  JSONFloat `json:"weibull_k"`  //ワイブル分布の形状母数 [-]
+	WeibullC  JSONFloat `json:"weibull_c"`  //ワイブル分布の尺度母数 [m/s]
+}
+
+// 風配図
+type WindRose struct {
+	Calm         float64      `json:"calm_speed"`    //静穏の風速 [m/s]
+	SpeedClasses []float64    `json:"speed_classes"` //風速階級の境界 [m/s]
+	Months       []int        `json:"months"`        //対象の月
+	Hours        []int        `json:"hours"`         //対象の時刻
+	Count        int          `json:"count"`         //対象の時間数 [h]
+	CalmFreq     float64      `json:"calm"`          //静穏の出現率 [%]
+	Prevailing   string       `json:"prevailing"`    //最多風向
+	MeanSpeed    JSONFloat    `json:"mean_speed"`    //静穏を含む平均風速 [m/s]
+	Sectors      []WindSector `json:"sectors"`       //方位別の統計
+}
+
+// 整数のリストを文字列 s から作成します。 ex) "6,7,8", "9-17", "12-2" (12,1,2)
+// 範囲は min から max の循環として扱います。空文字列の場合は空のリストを返します。
+func ParseIntRanges(s string, min int, max int) ([]int, error) {
+	values := []int{}
+	if strings.TrimSpace(s) == "" {
+		return values, nil
+	}
+	seen := map[int]bool{}
+	add := func(v int) {
+		if !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	parse := func(item string) (int, error) {
+		v, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil || v < min || v > max {
+			return 0, fmt.Errorf("invalid value %q (%d-%d)", item, min, max)
+		}
+		return v, nil
+	}
+	for _, item := range strings.Split(s, ",") {
+		r := strings.SplitN(item, "-", 2)
+		start, err := parse(r[0])
+		if err != nil {
+			return nil, err
+		}
+		end := start
+		if len(r) == 2 {
+			end, err = parse(r[1])
+			if err != nil {
+				return nil, err
+			}
+		}
+		for v := start; ; v++ {
+			if v > max {
+				v = min
+			}
+			add(v)
+			if v == end {
+				break
+			}
+		}
+	}
+	sort.Ints(values)
+	return values, nil
+}
+
+// 風速 W_spd と風向 W_dir から、条件 cfg の時刻を対象に16方位・風速階級別の風配図を作成します。
+// 静穏の時刻は方位別の集計から除き、ワイブル分布の母数は方位別の静穏を除く風速の平均と標準偏差から
+// Justus の経験式により求めます。
+//
+// 参照)
+//
+//	Justus CG, Hargraves WR, Mikhail A, Graber D. Methods for estimating wind speed frequency distributions.
+//	Journal of Applied Meteorology 1978; 17(3): 350-353.
+func (msm *MsmTarget) CalcWindRose(cfg WindRoseConfig) *WindRose {
+	wr := &WindRose{
+		Calm:         cfg.Calm,
+		SpeedClasses: cfg.SpeedClasses,
+		Months:       cfg.Months,
+		Hours:        cfg.Hours,
+	}
+
+	contains := func(list []int, v int) bool {
+		if len(list) == 0 {
+			return true
+		}
+		for _, x := range list {
+			if x == v {
+				return true
+			}
+		}
+		return false
+	}
+
+	speeds := make([][]float64, 16)
+	counts := make([][]int, 16)
+	for k := range counts {
+		counts[k] = make([]int, len(cfg.SpeedClasses)+1)
+	}
+	var calm int
+	var sum float64
+	for i := 0; i < len(msm.date); i++ {
+		if !contains(cfg.Months, int(msm.date[i].Month())) || !contains(cfg.Hours, msm.date[i].Hour()) {
+			continue
+		}
+		wr.Count++
+		sum += msm.W_spd[i]
+		if msm.W_spd[i] < cfg.Calm {
+			calm++
+			continue
+		}
+		k := int(math.Round(msm.W_dir[i]/22.5)) % 16
+		speeds[k] = append(speeds[k], msm.W_spd[i])
+		counts[k][IndexOf(msm.W_spd[i], cfg.SpeedClasses)]++
+	}
+
+	wr.MeanSpeed = JSONFloat(math.NaN())
+	if wr.Count > 0 {
+		wr.CalmFreq = float64(calm) / float64(wr.Count) * 100
+		wr.MeanSpeed = JSONFloat(sum / float64(wr.Count))
+	}
+
+	prevailing := -1
+	for k := 0; k < 16; k++ {
+		s := WindSector{
+			Direction: windDirectionNames[k],
+			Angle:     22.5 * float64(k),
+			Frequency: make([]float64, len(counts[k])),
+			MeanSpeed: JSONFloat(math.NaN()),
+			WeibullK:  JSONFloat(math.NaN()),
+			WeibullC:  JSONFloat(math.NaN()),
+		}
+		for j, n := range counts[k] {
+			if wr.Count > 0 {
+				s.Frequency[j] = float64(n) / float64(wr.Count) * 100
+				s.Total += s.Frequency[j]
+			}
+		}
+		if len(speeds[k]) > 0 {
+			s.MeanSpeed = JSONFloat(mean(speeds[k]))
+			kw, cw := weibullMoments(speeds[k])
+			s.WeibullK, s.WeibullC = JSONFloat(kw), JSONFloat(cw)
+			if prevailing < 0 || len(speeds[k]) > len(speeds[prevailing]) {
+				prevailing = k
+			}
+		}
+		wr.Sectors = append(wr.Sectors, s)
+	}
+	if prevailing >= 0 {
+		wr.Prevailing = windDirectionNames[prevailing]
+	}
+
+	return wr
+}
+
+// 風速 v の平均と標準偏差から Justus の経験式によりワイブル分布の形状母数 k と尺度母数 c を求めます。
+// 標本数が2未満または標準偏差が0の場合は NaN を返します。
+func weibullMoments(v []float64) (float64, float64) {
+	if len(v) < 2 {
+		return math.NaN(), math.NaN()
+	}
+	mu, sigma := meanAndStd(v)
+	if !(sigma > 0) || !(mu > 0) {
+		return math.NaN(), math.NaN()
+	}
+	k := math.Pow(sigma/mu, -1.086)
+	c := mu / math.Gamma(1+1/k)
+	return k, c
+}
+
+// 風速階級の名称を返します。 ex) 0-1, 1-2, 8-
+func (wr *WindRose) speedClassName(j int) string {
+	lower := wr.Calm
+	if j > 0 {
+		lower = wr.SpeedClasses[j-1]
+	}
+	name := strconv.FormatFloat(lower, 'f', -1, 64) + "-"
+	if j < len(wr.SpeedClasses) {
+		name += strconv.FormatFloat(wr.SpeedClasses[j], 'f', -1, 64)
+	}
+	return name
+}
+
+// 風配図をCSV形式で出力します。
+// 1行目に静穏の出現率を、続いて方位別に風速階級ごとの出現率 [%]、出現率、平均風速、ワイブル分布の母数を出力します。
+func (wr *WindRose) ToCSV(buf *bytes.Buffer) {
+	f := func(v float64, prec int) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		return strconv.FormatFloat(v, 'f', prec, 64)
+	}
+
+	buf.WriteString("direction,angle")
+	for j := range wr.SpeedClasses {
+		buf.WriteString(",")
+		buf.WriteString(wr.speedClassName(j))
+	}
+	buf.WriteString(",")
+	buf.WriteString(wr.speedClassName(len(wr.SpeedClasses)))
+	buf.WriteString(",total,mean_speed,weibull_k,weibull_c\n")
+
+	buf.WriteString("Calm,")
+	buf.WriteString(strings.Repeat(",", len(wr.SpeedClasses)+2))
+	buf.WriteString(f(wr.CalmFreq, 2))
+	buf.WriteString(",,,\n")
+
+	for _, s := range wr.Sectors {
+		buf.WriteString(s.Direction)
+		buf.WriteString(",")
+		buf.WriteString(strconv.FormatFloat(s.Angle, 'f', -1, 64))
+		for _, v := range s.Frequency {
+			buf.WriteString(",")
+			buf.WriteString(f(v, 2))
+		}
+		buf.WriteString(",")
+		buf.WriteString(strings.Join([]string{
+			f(s.Total, 2),
+			f(float64(s.MeanSpeed), 2),
+			f(float64(s.WeibullK), 3),
+			f(float64(s.WeibullC), 3),
+		}, ","))
+		buf.WriteString("\n")
+	}
+}
+
+// 風配図をJSON形式で出力します。
+func (wr *WindRose) ToJSON(buf *bytes.Buffer) {
+	b, err := json.MarshalIndent(wr, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	buf.Write(b)
+	buf.WriteString("\n")
+}
+
+// 風速階級の色
+var windRoseColors = []string{"#c6dbef", "#9ecae1", "#6baed6", "#4292c6", "#2171b5", "#08519c", "#08306b"}
+
+// 風配図をSVG形式で出力します。
+// 方位別に風速階級ごとの出現率を積み上げた扇形で描き、半径は出現率が最大の方位を外周とします。
+func (wr *WindRose) ToSVG(buf *bytes.Buffer, title string) {
+	const size = 480.0
+	const cx, cy, R = 240.0, 250.0, 180.0
+
+	max := 0.0
+	for _, s := range wr.Sectors {
+		max = math.Max(max, s.Total)
+	}
+	// 目盛りの間隔 [%]
+	step := 1.0
+	for _, v := range []float64{1, 2, 5, 10, 20, 50} {
+		step = v
+		if max/v <= 5 {
+			break
+		}
+	}
+	rmax := math.Max(step, math.Ceil(max/step)*step)
+
+	p := func(r float64, angle float64) (float64, float64) {
+		a := angle * math.Pi / 180
+		return cx + r*math.Sin(a), cy - r*math.Cos(a)
+	}
+
+	buf.WriteString(fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%g\" height=\"%g\" viewBox=\"0 0 %g %g\">\n", size, size+40, size, size+40))
+	buf.WriteString("<rect width=\"100%\" height=\"100%\" fill=\"white\"/>\n")
+	buf.WriteString(fmt.Sprintf("<text x=\"%g\" y=\"24\" font-family=\"sans-serif\" font-size=\"16\" text-anchor=\"middle\">%s</text>\n", cx, svgEscape(title)))
+
+	// 目盛り
+	for v := step; v <= rmax+1e-9; v += step {
+		r := R * v / rmax
+		buf.WriteString(fmt.Sprintf("<circle cx=\"%g\" cy=\"%g\" r=\"%.1f\" fill=\"none\" stroke=\"#ccc\"/>\n", cx, cy, r))
+		x, y := p(r, 67.5)
+		buf.WriteString(fmt.Sprintf("<text x=\"%.1f\" y=\"%.1f\" font-family=\"sans-serif\" font-size=\"10\" fill=\"#666\">%g%%</text>\n", x, y, v))
+	}
+	for k, s := range wr.Sectors {
+		x, y := p(R, s.Angle)
+		buf.WriteString(fmt.Sprintf("<line x1=\"%g\" y1=\"%g\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"#eee\"/>\n", cx, cy, x, y))
+		if k%2 == 0 {
+			x, y := p(R+16, s.Angle)
+			buf.WriteString(fmt.Sprintf("<text x=\"%.1f\" y=\"%.1f\" font-family=\"sans-serif\" font-size=\"12\" text-anchor=\"middle\" dominant-baseline=\"middle\">%s</text>\n", x, y, windRoseLabels[k]))
+		}
+	}
+
+	// 扇形
+	for _, s := range wr.Sectors {
+		var cum float64
+		for j, v := range s.Frequency {
+			if v <= 0 {
+				continue
+			}
+			r0 := R * cum / rmax
+			cum += v
+			r1 := R * cum / rmax
+			a0, a1 := s.Angle-11.25*0.9, s.Angle+11.25*0.9
+			x0, y0 := p(r0, a0)
+			x1, y1 := p(r1, a0)
+			x2, y2 := p(r1, a1)
+			x3, y3 := p(r0, a1)
+			buf.WriteString(fmt.Sprintf("<path d=\"M%.1f,%.1f L%.1f,%.1f A%.1f,%.1f 0 0 1 %.1f,%.1f L%.1f,%.1f A%.1f,%.1f 0 0 0 %.1f,%.1f Z\" fill=\"%s\" stroke=\"white\" stroke-width=\"0.5\"/>\n",
+				x0, y0, x1, y1, r1, r1, x2, y2, x3, y3, r0, r0, x0, y0, windRoseColors[j%len(windRoseColors)]))
+		}
+	}
+
+	// 凡例
+	y := size + 10
+	x := 20.0
+	for j := 0; j <= len(wr.SpeedClasses); j++ {
+		buf.WriteString(fmt.Sprintf("<rect x=\"%g\" y=\"%g\" width=\"12\" height=\"12\" fill=\"%s\"/>\n", x, y, windRoseColors[j%len(windRoseColors)]))
+		buf.WriteString(fmt.Sprintf("<text x=\"%g\" y=\"%g\" font-family=\"sans-serif\" font-size=\"11\">%s m/s</text>\n", x+16, y+10, wr.speedClassName(j)))
+		x += 62
+	}
+	buf.WriteString(fmt.Sprintf("<text x=\"%g\" y=\"%g\" font-family=\"sans-serif\" font-size=\"11\" text-anchor=\"end\">Calm %.1f%%</text>\n", size-10, size-10, wr.CalmFreq))
+	buf.WriteString("</svg>\n")
+}
+
+// 風配図の方位の表示名
+var windRoseLabels = []string{"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW"}
+
+// SVGのテキストの特殊文字をエスケープします。
+func svgEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
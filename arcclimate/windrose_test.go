@@ -0,0 +1,101 @@
+package arcclimate
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 整数のリスト
+func Test_ParseIntRanges(t *testing.T) {
+	v, err := ParseIntRanges("6,7,8", 1, 12)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{6, 7, 8}, v)
+
+	v, err = ParseIntRanges("12-2", 1, 12)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 12}, v)
+
+	v, err = ParseIntRanges("22-1, 9", 0, 23)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1, 9, 22, 23}, v)
+
+	v, err = ParseIntRanges("", 0, 23)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{}, v)
+
+	_, err = ParseIntRanges("13", 1, 12)
+	assert.NotNil(t, err)
+}
+
+// ワイブル分布の母数
+func Test_weibullMoments(t *testing.T) {
+	// k=2 のワイブル分布の平均と標準偏差
+	c := 5.0
+	mu := c * math.Gamma(1.5)
+	sigma := c * math.Sqrt(math.Gamma(2)-math.Gamma(1.5)*math.Gamma(1.5))
+	// 2点の標本の標準偏差は差の1/√2
+	d := sigma / math.Sqrt2
+	k, cw := weibullMoments([]float64{mu - d, mu + d})
+	assert.InDelta(t, 2.0, k, 0.05)
+	assert.InDelta(t, c, cw, 0.05)
+
+	k, _ = weibullMoments([]float64{3})
+	assert.True(t, math.IsNaN(k))
+}
+
+// 風配図
+func Test_CalcWindRose(t *testing.T) {
+	// 風速 2, 3, 4 m/s の繰り返し, 風向は時刻ごとに 22.5° ずつ
+	msm := designTestTarget(2011, 2011)
+	msm.W_spd[0] = 0.1
+
+	wr := msm.CalcWindRose(WindRoseConfig{Calm: DefaultCalmSpeed, SpeedClasses: DefaultWindSpeedClasses})
+	assert.Equal(t, 365*24, wr.Count)
+	assert.InDelta(t, 100.0/(365*24), wr.CalmFreq, 1e-9)
+	// 0時から7時の方位は1日2回出現し、北は静穏の1時間を除く
+	assert.Equal(t, "NNE", wr.Prevailing)
+	var total float64
+	for _, s := range wr.Sectors {
+		total += s.Total
+	}
+	assert.InDelta(t, 100-wr.CalmFreq, total, 1e-9)
+
+	// 北の風速階級 (時刻 0 と 16 時)
+	n := wr.Sectors[0]
+	assert.Equal(t, 0.0, n.Frequency[0])
+	assert.True(t, n.Frequency[2] > 0)
+	assert.False(t, math.IsNaN(float64(n.WeibullK)))
+
+	// 時刻を限定 (9時は 22.5*9 = 202.5° 風速 2 m/s)
+	wr = msm.CalcWindRose(WindRoseConfig{Calm: DefaultCalmSpeed, SpeedClasses: DefaultWindSpeedClasses, Months: []int{7}, Hours: []int{9}})
+	assert.Equal(t, 31, wr.Count)
+	assert.Equal(t, "SSW", wr.Prevailing)
+	assert.InDelta(t, 100.0, wr.Sectors[9].Frequency[2], 1e-9)
+	assert.InDelta(t, 2.0, float64(wr.MeanSpeed), 1e-9)
+	assert.True(t, math.IsNaN(float64(wr.Sectors[9].WeibullK)))
+
+	buf := bytes.NewBuffer(nil)
+	wr.ToCSV(buf)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, "direction,angle,0.3-1,1-2,2-3,3-4,4-6,6-8,8-,total,mean_speed,weibull_k,weibull_c", lines[0])
+	assert.Equal(t, strings.Count(lines[0], ","), strings.Count(lines[1], ","))
+	assert.Equal(t, "Calm,,,,,,,,,0.00,,,", lines[1])
+	assert.Equal(t, "SSW,202.5,0.00,0.00,100.00,0.00,0.00,0.00,0.00,100.00,2.00,,", lines[11])
+
+	buf.Reset()
+	wr.ToJSON(buf)
+	assert.Contains(t, buf.String(), `"prevailing": "SSW"`)
+	assert.Contains(t, buf.String(), `"weibull_k": null`)
+
+	buf.Reset()
+	wr.ToSVG(buf, "test <1>")
+	svg := buf.String()
+	assert.True(t, strings.HasPrefix(svg, "<svg "))
+	assert.True(t, strings.HasSuffix(svg, "</svg>\n"))
+	assert.Contains(t, svg, "test &lt;1&gt;")
+	assert.Equal(t, 1, strings.Count(svg, "<path "))
+}
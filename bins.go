@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/akamensky/argparse"
+	"github.com/udawtr/arcclimate-go/arcclimate"
+)
+
+// bins コマンド: 作成した気象データから乾球温度のビンデータを作成します。
+func runBins(args []string) {
+	parser := argparse.NewParser("bins", "Creates dry-bulb temperature bin data from the interpolated meteorological data")
+
+	site := addSiteArguments(parser)
+
+	width := parser.Float("", "width", &argparse.Options{
+		Default: arcclimate.DefaultBinWidth,
+		Help:    "乾球温度のビン幅 [℃]"})
+
+	blocks := parser.String("", "blocks", &argparse.Options{
+		Default: "0,4,8,12,16,20",
+		Help:    "時間帯の開始時刻 カンマ区切り"})
+
+	filename := parser.String("o", "output", &argparse.Options{
+		Default: "",
+		Help:    "乾球温度のビンデータの保存ファイルパス(省略時は標準出力)"})
+
+	mrWidth := parser.Float("", "mr_width", &argparse.Options{
+		Default: arcclimate.DefaultHumidityBinWidth,
+		Help:    "2次元ビンの重量絶対湿度のビン幅 [g/kg(DA)]"})
+
+	jointFilename := parser.String("", "joint_output", &argparse.Options{
+		Default: "",
+		Help:    "乾球温度・重量絶対湿度の2次元ビンデータの保存ファイルパス"})
+
+	envelopes := parser.StringList("", "envelope", &argparse.Options{
+		Help: "外気利用の範囲 名称:乾球温度の下限:上限:重量絶対湿度の下限:上限 (空欄は制限なし) 複数指定可"})
+
+	envelopeFilename := parser.String("", "envelope_output", &argparse.Options{
+		Default: "",
+		Help:    "外気利用の範囲内の時間数の保存ファイルパス"})
+
+	err := parser.Parse(args)
+	if err != nil {
+		fmt.Print(parser.Usage(err))
+		os.Exit(1)
+	}
+
+	if !(*width > 0) || !(*mrWidth > 0) {
+		fmt.Fprintln(os.Stderr, "Error: bin width must be positive")
+		os.Exit(1)
+	}
+	blockHours, err := arcclimate.ParseBinBlocks(*blocks)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	envs := []arcclimate.Envelope{}
+	for _, s := range *envelopes {
+		e, err := arcclimate.ParseEnvelope(s)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		envs = append(envs, e)
+	}
+
+	// 補間処理
+	res := site.interpolate("none", false)
+
+	// ビンデータの作成
+	log.Printf("ビンデータの作成")
+	var buf *bytes.Buffer = bytes.NewBuffer([]byte{})
+	res.CalcBins(*width, blockHours).ToCSV(buf)
+	if *filename == "" {
+		fmt.Print(buf.String())
+	} else {
+		log.Printf("CSV保存: %s", *filename)
+		err := os.WriteFile(*filename, buf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	if *jointFilename != "" {
+		log.Printf("CSV保存: %s", *jointFilename)
+		var jbuf *bytes.Buffer = bytes.NewBuffer([]byte{})
+		res.CalcJointBins(*width, *mrWidth).ToCSV(jbuf)
+		err := os.WriteFile(*jointFilename, jbuf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	if *envelopeFilename != "" {
+		log.Printf("CSV保存: %s", *envelopeFilename)
+		var ebuf *bytes.Buffer = bytes.NewBuffer([]byte{})
+		arcclimate.EnvelopeHoursToCSV(ebuf, res.CountEnvelopeHours(envs, blockHours), blockHours)
+		err := os.WriteFile(*envelopeFilename, ebuf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	log.Printf("計算が終了しました")
+}
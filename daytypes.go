@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/akamensky/argparse"
+)
+
+// daytypes コマンド: 作成した気象データから夏日・真夏日・猛暑日・熱帯夜・冬日・真冬日の日数を数えます。
+func runDayTypes(args []string) {
+	parser := argparse.NewParser("daytypes", "Counts the JMA day types (summer days, tropical nights, etc.) of the interpolated meteorological data")
+
+	site := addSiteArguments(parser)
+
+	filename := parser.String("o", "output", &argparse.Options{
+		Default: "",
+		Help:    "月別の日数の保存ファイルパス(省略時は標準出力)"})
+
+	annualFilename := parser.String("", "annual_output", &argparse.Options{
+		Default: "",
+		Help:    "年別の日数と最長の連続日数の保存ファイルパス"})
+
+	longestFilename := parser.String("", "longest_output", &argparse.Options{
+		Default: "",
+		Help:    "全期間で最長の連続期間の保存ファイルパス"})
+
+	err := parser.Parse(args)
+	if err != nil {
+		fmt.Print(parser.Usage(err))
+		os.Exit(1)
+	}
+
+	// 補間処理
+	res := site.interpolate("none", false)
+
+	// 階級別日数の計算
+	log.Printf("階級別日数の計算")
+	c := res.CountDayTypes()
+
+	// 保存
+	var buf *bytes.Buffer = bytes.NewBuffer([]byte{})
+	c.MonthlyToCSV(buf)
+	if *filename == "" {
+		fmt.Print(buf.String())
+	} else {
+		log.Printf("CSV保存: %s", *filename)
+		err := os.WriteFile(*filename, buf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	if *annualFilename != "" {
+		log.Printf("CSV保存: %s", *annualFilename)
+		var abuf *bytes.Buffer = bytes.NewBuffer([]byte{})
+		c.AnnualToCSV(abuf)
+		err := os.WriteFile(*annualFilename, abuf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	if *longestFilename != "" {
+		log.Printf("CSV保存: %s", *longestFilename)
+		var lbuf *bytes.Buffer = bytes.NewBuffer([]byte{})
+		c.LongestToCSV(lbuf)
+		err := os.WriteFile(*longestFilename, lbuf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	log.Printf("計算が終了しました")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/akamensky/argparse"
+	"github.com/udawtr/arcclimate-go/arcclimate"
+)
+
+// degreedays コマンド: 作成した気象データから暖房度日・冷房度日を計算します。
+func runDegreeDays(args []string) {
+	parser := argparse.NewParser("degreedays", "Calculates heating and cooling degree days from the interpolated meteorological data")
+
+	site := addSiteArguments(parser)
+
+	heating := parser.StringList("", "heating", &argparse.Options{
+		Default: arcclimate.DefaultHeatingDegreeDays,
+		Help:    "暖房度日の基準温度 基準温度(18) または 基準温度-限界温度(18-14) 負の温度も可(-5--10) 複数指定可"})
+
+	cooling := parser.StringList("", "cooling", &argparse.Options{
+		Default: arcclimate.DefaultCoolingDegreeDays,
+		Help:    "冷房度日の基準温度 基準温度(24) または 基準温度-限界温度(24-22) 負の温度も可 複数指定可"})
+
+	method := parser.Selector("", "method", []string{arcclimate.DegreeDayDaily, arcclimate.DegreeDayHourly}, &argparse.Options{
+		Default: arcclimate.DegreeDayDaily,
+		Help:    "積算方法 日平均気温による度日=daily(デフォルト), 時刻別の気温による度時=hourly"})
+
+	filename := parser.String("o", "output", &argparse.Options{
+		Default: "",
+		Help:    "月別の度日の保存ファイルパス(省略時は標準出力)"})
+
+	annualFilename := parser.String("", "annual_output", &argparse.Options{
+		Default: "",
+		Help:    "年別の度日の保存ファイルパス"})
+
+	err := parser.Parse(args)
+	if err != nil {
+		fmt.Print(parser.Usage(err))
+		os.Exit(1)
+	}
+
+	// 度日の定義
+	specs := []arcclimate.DegreeDaySpec{}
+	for _, list := range []struct {
+		values  []string
+		heating bool
+	}{{*heating, true}, {*cooling, false}} {
+		for _, s := range list.values {
+			spec, err := arcclimate.ParseDegreeDaySpec(s, list.heating)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(1)
+			}
+			specs = append(specs, spec)
+		}
+	}
+
+	// 補間処理
+	res := site.interpolate("none", false)
+
+	// 度日の計算
+	log.Printf("度日の計算")
+	dd := res.CalcDegreeDays(specs, *method)
+
+	// 保存
+	var buf *bytes.Buffer = bytes.NewBuffer([]byte{})
+	dd.MonthlyToCSV(buf)
+	if *filename == "" {
+		fmt.Print(buf.String())
+	} else {
+		log.Printf("CSV保存: %s", *filename)
+		err := os.WriteFile(*filename, buf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	if *annualFilename != "" {
+		log.Printf("CSV保存: %s", *annualFilename)
+		var abuf *bytes.Buffer = bytes.NewBuffer([]byte{})
+		dd.AnnualToCSV(abuf)
+		err := os.WriteFile(*annualFilename, abuf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	log.Printf("計算が終了しました")
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/akamensky/argparse"
+	"github.com/udawtr/arcclimate-go/arcclimate"
+)
+
+// drivingrain コマンド: 作成した気象データから外壁面の風雨量(ISO 15927-3)を計算します。
+func runDrivingRain(args []string) {
+	parser := argparse.NewParser("drivingrain", "Calculates wind-driven rain on walls by ISO 15927-3 from the interpolated meteorological data")
+
+	site := addSiteArguments(parser)
+
+	format := parser.Selector("f", "format", []string{"CSV", "JSON"}, &argparse.Options{
+		Default: "CSV",
+		Help:    "方位別の風雨量指数の出力形式 CSV or JSON"})
+
+	filename := parser.String("o", "output", &argparse.Options{
+		Default: "",
+		Help:    "方位別の風雨量指数の保存ファイルパス(省略時は標準出力)"})
+
+	orientation := parser.Float("", "orientation", &argparse.Options{
+		Default: 180.0,
+		Help:    "外壁面の法線の方位角 [°] (北=0, 時計回り)"})
+
+	height := parser.Float("", "height", &argparse.Options{
+		Default: 10.0,
+		Help:    "外壁面の高さ [m]"})
+
+	terrain := parser.Selector("", "terrain", []string{"I", "II", "III", "IV"}, &argparse.Options{
+		Default: "III",
+		Help:    "地表面粗度区分 I:海岸, II:農地, III:郊外・森林(デフォルト), IV:市街地"})
+
+	topography := parser.Float("", "topography", &argparse.Options{
+		Default: 1.0,
+		Help:    "地形係数 C_T"})
+
+	obstruction := parser.Float("", "obstruction", &argparse.Options{
+		Default: 1.0,
+		Help:    "遮蔽係数 O"})
+
+	wallFactor := parser.Float("", "wall_factor", &argparse.Options{
+		Default: arcclimate.DefaultWallFactor,
+		Help:    "壁係数 W"})
+
+	wallFilename := parser.String("", "wall_output", &argparse.Options{
+		Default: "",
+		Help:    "外壁面の時刻別の風雨量の保存ファイルパス"})
+
+	err := parser.Parse(args)
+	if err != nil {
+		fmt.Print(parser.Usage(err))
+		os.Exit(1)
+	}
+
+	// 補間処理
+	res := site.interpolate("none", false)
+
+	// 方位別の風雨量指数
+	log.Printf("風雨量指数の計算")
+	dr := res.CalcDrivingRainIndex()
+
+	var buf *bytes.Buffer = bytes.NewBuffer([]byte{})
+	if *format == "CSV" {
+		dr.ToCSV(buf)
+	} else {
+		dr.ToJSON(buf)
+	}
+	if *filename == "" {
+		fmt.Print(buf.String())
+	} else {
+		log.Printf("風雨量指数の保存: %s", *filename)
+		err := os.WriteFile(*filename, buf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// 外壁面の時刻別の風雨量
+	if *wallFilename != "" {
+		wr, err := res.CalcWallRain(arcclimate.WallRainConfig{
+			Orientation: *orientation,
+			Height:      *height,
+			Terrain:     *terrain,
+			Topography:  *topography,
+			Obstruction: *obstruction,
+			WallFactor:  *wallFactor,
+		})
+		if err != nil {
+			panic(err)
+		}
+		log.Printf("外壁面の風雨量: 年間 %.1f L/m2, 連続期間指数 %.1f L/m2", wr.Annual, float64(wr.Spell))
+
+		log.Printf("外壁面の風雨量の保存: %s", *wallFilename)
+		var wbuf *bytes.Buffer = bytes.NewBuffer([]byte{})
+		wr.ToCSV(wbuf)
+		err = os.WriteFile(*wallFilename, wbuf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	log.Printf("計算が終了しました")
+}
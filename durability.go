@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/akamensky/argparse"
+)
+
+// durability コマンド: 作成した気象データから月別・年別の材料の耐久性の指標を出力します。
+func runDurability(args []string) {
+	parser := argparse.NewParser("durability", "Calculates material durability indices by month and year from the interpolated meteorological data")
+
+	site := addSiteArguments(parser)
+
+	format := parser.Selector("f", "format", []string{"CSV", "JSON"}, &argparse.Options{
+		Default: "CSV",
+		Help:    "出力形式 CSV or JSON"})
+
+	filename := parser.String("o", "output", &argparse.Options{
+		Default: "",
+		Help:    "耐久性の指標の保存ファイルパス(省略時は標準出力)"})
+
+	err := parser.Parse(args)
+	if err != nil {
+		fmt.Print(parser.Usage(err))
+		os.Exit(1)
+	}
+
+	// 補間処理
+	res := site.interpolate("none", false)
+
+	// 耐久性の指標の計算
+	log.Printf("耐久性の指標の計算")
+	durability := res.CalcDurability()
+
+	// 保存
+	var buf *bytes.Buffer = bytes.NewBuffer([]byte{})
+	switch *format {
+	case "CSV":
+		durability.ToCSV(buf)
+	case "JSON":
+		durability.ToJSON(buf)
+	}
+	if *filename == "" {
+		fmt.Print(buf.String())
+	} else {
+		log.Printf("耐久性の指標の保存: %s", *filename)
+		err := os.WriteFile(*filename, buf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	log.Printf("計算が終了しました")
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/akamensky/argparse"
 	"github.com/udawtr/arcclimate-go/arcclimate"
@@ -14,94 +16,202 @@ import (
 func main() {
 	log.SetFlags(log.Lmicroseconds)
 
+	// サブコマンドの処理
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "pv":
+			runPV(os.Args[1:])
+			return
+		case "degreedays":
+			runDegreeDays(os.Args[1:])
+			return
+		case "summary":
+			runSummary(os.Args[1:])
+			return
+		case "daytypes":
+			runDayTypes(os.Args[1:])
+			return
+		case "bins":
+			runBins(os.Args[1:])
+			return
+		case "windrose":
+			runWindRose(os.Args[1:])
+			return
+		case "drivingrain":
+			runDrivingRain(os.Args[1:])
+			return
+		case "durability":
+			runDurability(os.Args[1:])
+			return
+		case "ventilation":
+			runVentilation(os.Args[1:])
+			return
+		}
+	}
+
 	// コマンドライン引数の処理
 	parser := argparse.NewParser("ArcClimate", "Creates a design meteorological data set for any specified point")
 
-	lat := parser.FloatPositional(&argparse.Options{
-		Default: 35.658,
-		Help:    "推計対象地点の緯度（10進法）"})
-
-	lon := parser.FloatPositional(&argparse.Options{
-		Default: 139.741,
-		Help:    "推計対象地点の経度（10進法）"})
+	site := addSiteArguments(parser)
 
 	filename := parser.String("o", "output", &argparse.Options{
 		Default: "",
 		Help:    "保存ファイルパス"})
 
-	startYear := parser.Int("", "start_year", &argparse.Options{
-		Default: 2011,
-		Help:    "出力する気象データの開始年（標準年データの検討期間も兼ねる）"})
-
-	endYear := parser.Int("", "end_year", &argparse.Options{
-		Default: 2020,
-		Help:    "出力する気象データの終了年（標準年データの検討期間も兼ねる）"})
-
-	mode := parser.Selector("", "mode", []string{"normal", "EA"}, &argparse.Options{
-		Default: "normal",
-		Help:    "計算モードの指定 標準=normal(デフォルト), 標準年=EA"})
-
 	format := parser.Selector("f", "file", []string{"CSV", "EPW", "HAS"}, &argparse.Options{
 		Default: "CSV",
 		Help:    "出力形式 CSV, EPW or HAS"})
 
-	modeEle := parser.Selector("", "mode_elevation", []string{"mesh", "api"}, &argparse.Options{
-		Default: "api",
-		Help:    "標高判定方法 API=api(デフォルト), メッシュデータ=mesh"})
+	modeClearSky := parser.Selector("", "mode_clearsky", []string{"none", "Ineichen", "Bird"}, &argparse.Options{
+		Default: "none",
+		Help:    "晴天日射量の計算方法 計算しない=none(デフォルト), Ineichen-Perez=Ineichen, Bird=Bird"})
+
+	linkeTurbidity := parser.String("", "linke_turbidity", &argparse.Options{
+		Default: "",
+		Help:    "月別リンケ混濁係数(緯度,経度,1月から12月の値)を記述したCSVファイルのパス (--mode_clearsky Ineichen の場合。省略時は可降水量から推定)"})
+
+	cloudCover := parser.Flag("", "cloud_cover", &argparse.Options{
+		Help: "雲量を推定して出力する(EPW形式の場合は常に推定)"})
+
+	illuminance := parser.Flag("", "illuminance", &argparse.Options{
+		Help: "照度および天頂輝度を計算して出力する(EPW形式の場合は常に計算)"})
+
+	snow := parser.Flag("", "snow", &argparse.Options{
+		Help: "降雪・積雪深・地表面反射率を計算して出力する(EPW形式の場合は常に計算)"})
+
+	psychrometrics := parser.Flag("", "psychrometrics", &argparse.Options{
+		Help: "露点温度・湿球温度・比エンタルピー・比容積・密度を計算して出力する"})
+
+	thermalIndex := parser.Flag("", "thermal_index", &argparse.Options{
+		Help: "暑さ指数(WBGT)・Heat Index・不快指数・体感温度を計算して出力する"})
+
+	wbgtOutput := parser.String("", "wbgt_output", &argparse.Options{
+		Default: "",
+		Help:    "年別の暑さ指数の危険度の区分ごとの時間数の保存ファイルパス"})
+
+	et0 := parser.Flag("", "et0", &argparse.Options{
+		Help: "FAO-56 の Penman-Monteith 式による基準蒸発散量を計算して出力する"})
+
+	et0Output := parser.String("", "et0_output", &argparse.Options{
+		Default: "",
+		Help:    "日別の基準蒸発散量と水収支の保存ファイルパス"})
+
+	et0MonthlyOutput := parser.String("", "et0_monthly_output", &argparse.Options{
+		Default: "",
+		Help:    "月別・年間の基準蒸発散量と水収支の保存ファイルパス"})
+
+	supplyWater := parser.Flag("", "supply_water", &argparse.Options{
+		Help: "省エネ基準の計算方法により日平均給水温度を推定して出力する"})
+
+	supplyWaterOutput := parser.String("", "supply_water_output", &argparse.Options{
+		Default: "",
+		Help:    "日別の給水温度の保存ファイルパス"})
+
+	supplyWaterRegion := parser.Int("", "supply_water_region", &argparse.Options{
+		Default: 0,
+		Help:    "給水温度の推定に用いる省エネ基準の地域区分 1-8 (給水温度の推定時は必須)"})
+
+	adaptiveOutput := parser.String("", "adaptive_output", &argparse.Options{
+		Default: "",
+		Help:    "日別の指数加重移動平均外気温度と適応的快適温度の範囲の保存ファイルパス"})
+
+	adaptiveMonthlyOutput := parser.String("", "adaptive_monthly_output", &argparse.Options{
+		Default: "",
+		Help:    "月別の適応的快適温度の範囲外の時間数の保存ファイルパス"})
+
+	designOutput := parser.String("", "design_output", &argparse.Options{
+		Default: "",
+		Help:    "設計用外気条件の保存ファイルパス(EPW形式の場合はヘッダーにも出力)"})
+
+	designFormat := parser.Selector("", "design_format", []string{"JSON", "CSV"}, &argparse.Options{
+		Default: "JSON",
+		Help:    "設計用外気条件の出力形式 JSON or CSV"})
+
+	periodsOutput := parser.String("", "periods_output", &argparse.Options{
+		Default: "",
+		Help:    "季節ごとの代表週・極端週の保存ファイルパス"})
 
-	disableEst := parser.Flag("", "disable_est", &argparse.Options{
-		Help: "標準年データの検討に日射量の推計値を使用しない（使用しない場合2018年以降のデータのみで作成）"})
+	groundOutput := parser.String("", "ground_output", &argparse.Options{
+		Default: "",
+		Help:    "月別の地中温度の保存ファイルパス(EPW形式の場合はヘッダーにも出力)"})
 
-	msmFileDir := parser.String("", "msm_file_dir", &argparse.Options{
-		Default: ".msm_cache",
-		Help:    "MSMファイルの格納ディレクトリ"})
+	groundDiffusivity := parser.Float("", "ground_diffusivity", &argparse.Options{
+		Default: arcclimate.DefaultSoilDiffusivity,
+		Help:    "地中温度の計算に用いる土壌の熱拡散率 [m2/day]"})
 
-	modeSep := parser.Selector("", "mode_separate", []string{"Nagata", "Watanabe", "Erbs", "Udagawa", "Perez"}, &argparse.Options{
-		Default: "Perez",
-		Help:    "直散分離の方法"})
+	horizonOutput := parser.String("", "horizon_output", &argparse.Options{
+		Default: "",
+		Help:    "地平線の仰角の分布の保存ファイルパス"})
 
 	err := parser.Parse(os.Args)
 	if err != nil {
 		fmt.Print(parser.Usage(err))
 	}
 
-	// MSMフォルダの作成
-	// os.MkdirAll(*msmFileDir, os.ModePerm)
+	if (*supplyWater || *supplyWaterOutput != "") && (*supplyWaterRegion < 1 || *supplyWaterRegion > len(arcclimate.SupplyWaterCoefficients)) {
+		fmt.Fprintln(os.Stderr, "Error: --supply_water_region (1-8) is required for the supply water temperature")
+		os.Exit(1)
+	}
 
-	// EA方式かつ日射量の推計値を使用しない場合に開始年が2018年以上となっているか確認
-	if *mode == "EA" {
-		if *disableEst {
-			if *startYear < 2018 {
-				log.Printf("--disable_estを設定した場合は開始年を2018年以降にする必要があります")
-				fmt.Fprintln(os.Stderr, "Error: If \"disable_est\" is set, the start year must be 2018 or later")
-				os.Exit(1)
-			} else {
-				*disableEst = false
-			}
+	// 補間処理
+	res := site.interpolateWithOptions(arcclimate.InterpolateOptions{
+		ModeClearSky:     *modeClearSky,
+		LinkeTurbidity:   *linkeTurbidity,
+		DesignConditions: *designOutput != "" || *format == "EPW",
+	})
+
+	// 雲量の推定
+	if *cloudCover || *format == "EPW" {
+		res.CalcCloudCover()
+	}
+
+	// 照度・天頂輝度の計算
+	if *illuminance || *format == "EPW" {
+		res.CalcIlluminance()
+	}
+
+	// 降雪・積雪深・地表面反射率の計算
+	if *snow || *format == "EPW" {
+		res.CalcSnow()
+	}
+
+	// 湿り空気の状態値の計算
+	if *psychrometrics {
+		res.CalcPsychrometrics()
+	}
+
+	// 暑熱・温熱環境指標の計算
+	if *thermalIndex || *wbgtOutput != "" {
+		res.CalcThermalIndices()
+	}
+
+	// 基準蒸発散量の計算
+	if *et0 || *et0Output != "" || *et0MonthlyOutput != "" {
+		res.CalcET0()
+	}
+
+	// 給水温度の推定
+	var supplyWaterDays []arcclimate.SupplyWaterDay
+	if *supplyWater || *supplyWaterOutput != "" {
+		log.Printf("給水温度の推定 %d地域", *supplyWaterRegion)
+		supplyWaterDays, err = res.CalcSupplyWaterTemperature(*supplyWaterRegion)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
 		}
 	}
 
-	// 補間処理 (0.3s)
-	res := arcclimate.Interpolate(
-		*lat,
-		*lon,
-		*startYear,
-		*endYear,
-		*modeEle,
-		*mode,
-		!*disableEst,
-		*modeSep,
-		false,
-		false,
-		*msmFileDir,
-	)
+	// 地中温度の計算
+	if *groundOutput != "" || *format == "EPW" {
+		res.Ground = res.CalcGroundTemperatures(*groundDiffusivity)
+	}
 
 	// 保存
 	var buf *bytes.Buffer = bytes.NewBuffer([]byte{})
 	if *format == "CSV" {
 		res.ToCSV(buf)
 	} else if *format == "EPW" {
-		res.ToEPW(buf, *lat, *lon)
+		res.ToEPW(buf, *site.lat, *site.lon)
 	} else if *format == "HAS" {
 		res.ToHAS(buf)
 	}
@@ -116,5 +226,150 @@ func main() {
 		}
 	}
 
+	// EnergyPlus の設計日(DDY)と気象統計(STAT)の保存
+	if *format == "EPW" {
+		if *filename == "" {
+			log.Printf("標準出力の場合はDDYおよびSTATを保存しません")
+		} else {
+			base := strings.TrimSuffix(*filename, filepath.Ext(*filename))
+
+			log.Printf("DDY保存: %s", base+".ddy")
+			var ddy *bytes.Buffer = bytes.NewBuffer([]byte{})
+			res.ToDDY(ddy, *site.lat, *site.lon)
+			err := os.WriteFile(base+".ddy", ddy.Bytes(), os.ModePerm)
+			if err != nil {
+				panic(err)
+			}
+
+			log.Printf("STAT保存: %s", base+".stat")
+			var stat *bytes.Buffer = bytes.NewBuffer([]byte{})
+			res.ToSTAT(stat, *site.lat, *site.lon)
+			err = os.WriteFile(base+".stat", stat.Bytes(), os.ModePerm)
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	// 暑さ指数の危険度の区分ごとの時間数の保存
+	if *wbgtOutput != "" {
+		log.Printf("暑さ指数の危険度の区分ごとの時間数の保存: %s", *wbgtOutput)
+		var wbuf *bytes.Buffer = bytes.NewBuffer([]byte{})
+		res.WBGTRiskHoursToCSV(wbuf)
+		err := os.WriteFile(*wbgtOutput, wbuf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// 基準蒸発散量と水収支の保存
+	if *et0Output != "" || *et0MonthlyOutput != "" {
+		days := res.DailyET0()
+
+		if *et0Output != "" {
+			log.Printf("日別の基準蒸発散量の保存: %s", *et0Output)
+			var ebuf *bytes.Buffer = bytes.NewBuffer([]byte{})
+			arcclimate.ET0DaysToCSV(ebuf, days)
+			err := os.WriteFile(*et0Output, ebuf.Bytes(), os.ModePerm)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		if *et0MonthlyOutput != "" {
+			log.Printf("月別の基準蒸発散量の保存: %s", *et0MonthlyOutput)
+			var ebuf *bytes.Buffer = bytes.NewBuffer([]byte{})
+			arcclimate.ET0MonthsToCSV(ebuf, arcclimate.MonthlyET0(days))
+			err := os.WriteFile(*et0MonthlyOutput, ebuf.Bytes(), os.ModePerm)
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	// 日別の給水温度の保存
+	if *supplyWaterOutput != "" {
+		log.Printf("日別の給水温度の保存: %s", *supplyWaterOutput)
+		var sbuf *bytes.Buffer = bytes.NewBuffer([]byte{})
+		arcclimate.SupplyWaterToCSV(sbuf, supplyWaterDays)
+		err := os.WriteFile(*supplyWaterOutput, sbuf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// 適応的快適温度の保存
+	if *adaptiveOutput != "" || *adaptiveMonthlyOutput != "" {
+		days := res.AdaptiveComfort()
+
+		if *adaptiveOutput != "" {
+			log.Printf("適応的快適温度の保存: %s", *adaptiveOutput)
+			var abuf *bytes.Buffer = bytes.NewBuffer([]byte{})
+			arcclimate.AdaptiveComfortToCSV(abuf, days)
+			err := os.WriteFile(*adaptiveOutput, abuf.Bytes(), os.ModePerm)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		if *adaptiveMonthlyOutput != "" {
+			log.Printf("適応的快適温度の範囲外の時間数の保存: %s", *adaptiveMonthlyOutput)
+			var abuf *bytes.Buffer = bytes.NewBuffer([]byte{})
+			arcclimate.AdaptiveComfortHoursToCSV(abuf, res.AdaptiveComfortMonthlyHours(days))
+			err := os.WriteFile(*adaptiveMonthlyOutput, abuf.Bytes(), os.ModePerm)
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	// 設計用外気条件の保存
+	if *designOutput != "" {
+		log.Printf("設計用外気条件の保存: %s", *designOutput)
+		var dbuf *bytes.Buffer = bytes.NewBuffer([]byte{})
+		if *designFormat == "JSON" {
+			res.Design.ToJSON(dbuf)
+		} else {
+			res.Design.ToCSV(dbuf)
+		}
+		err := os.WriteFile(*designOutput, dbuf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// 地中温度の保存
+	if *groundOutput != "" {
+		log.Printf("地中温度の保存: %s", *groundOutput)
+		var gbuf *bytes.Buffer = bytes.NewBuffer([]byte{})
+		res.Ground.ToCSV(gbuf)
+		err := os.WriteFile(*groundOutput, gbuf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// 代表週・極端週の保存
+	if *periodsOutput != "" {
+		log.Printf("代表週・極端週の保存: %s", *periodsOutput)
+		var pbuf *bytes.Buffer = bytes.NewBuffer([]byte{})
+		arcclimate.TypicalExtremePeriodsToCSV(pbuf, res.TypicalExtremePeriodsByYear())
+		err := os.WriteFile(*periodsOutput, pbuf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// 地平線の仰角の分布の保存
+	if *horizonOutput != "" && res.Horizon != nil {
+		log.Printf("地平線の仰角の分布の保存: %s", *horizonOutput)
+		var hbuf *bytes.Buffer = bytes.NewBuffer([]byte{})
+		res.Horizon.ToCSV(hbuf)
+		err := os.WriteFile(*horizonOutput, hbuf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	log.Printf("計算が終了しました")
 }
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/akamensky/argparse"
+	"github.com/udawtr/arcclimate-go/arcclimate"
+)
+
+// pv コマンド: 作成した気象データから太陽光発電量を推計します。
+func runPV(args []string) {
+	parser := argparse.NewParser("pv", "Estimates photovoltaic yield from the interpolated meteorological data")
+
+	site := addSiteArguments(parser)
+
+	configPath := parser.String("c", "config", &argparse.Options{
+		Required: true,
+		Help:     "太陽電池アレイの設定ファイル(JSON)のパス"})
+
+	filename := parser.String("o", "output", &argparse.Options{
+		Default: "",
+		Help:    "時別の発電量の保存ファイルパス"})
+
+	monthlyFilename := parser.String("", "monthly_output", &argparse.Options{
+		Default: "",
+		Help:    "月別の発電量の保存ファイルパス(省略時は標準出力)"})
+
+	err := parser.Parse(args)
+	if err != nil {
+		fmt.Print(parser.Usage(err))
+		os.Exit(1)
+	}
+
+	// 設定の読込
+	file, err := os.Open(*configPath)
+	if err != nil {
+		panic(err)
+	}
+	config, err := arcclimate.ReadPVConfig(file)
+	file.Close()
+	if err != nil {
+		log.Printf("設定ファイルを読み込めません: %s", *configPath)
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+
+	// 補間処理
+	res := site.interpolate("none", false)
+
+	// 積雪に応じた地表面反射率
+	res.CalcSnow()
+
+	// 発電量の推計
+	log.Printf("太陽光発電量の推計")
+	pv := res.SimulatePV(config.Arrays)
+
+	// 保存
+	if *filename != "" {
+		log.Printf("CSV保存: %s", *filename)
+		var buf *bytes.Buffer = bytes.NewBuffer([]byte{})
+		pv.ToCSV(buf)
+		err := os.WriteFile(*filename, buf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	var mbuf *bytes.Buffer = bytes.NewBuffer([]byte{})
+	pv.MonthlyToCSV(mbuf)
+	if *monthlyFilename == "" {
+		fmt.Print(mbuf.String())
+	} else {
+		log.Printf("CSV保存: %s", *monthlyFilename)
+		err := os.WriteFile(*monthlyFilename, mbuf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	log.Printf("計算が終了しました")
+}
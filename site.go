@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/akamensky/argparse"
+	"github.com/udawtr/arcclimate-go/arcclimate"
+)
+
+// 推計対象地点と気象データの作成方法に関するコマンドライン引数
+type siteArguments struct {
+	lat        *float64
+	lon        *float64
+	startYear  *int
+	endYear    *int
+	mode       *string
+	modeEle    *string
+	disableEst *bool
+	msmFileDir *string
+	modeSep    *string
+	horizon    *string
+	windMode   *string
+}
+
+// 各コマンドで共通の推計対象地点と気象データの作成方法に関する引数を parser に追加します。
+func addSiteArguments(parser *argparse.Parser) *siteArguments {
+	site := &siteArguments{}
+
+	site.lat = parser.FloatPositional(&argparse.Options{
+		Default: 35.658,
+		Help:    "推計対象地点の緯度（10進法）"})
+
+	site.lon = parser.FloatPositional(&argparse.Options{
+		Default: 139.741,
+		Help:    "推計対象地点の経度（10進法）"})
+
+	site.startYear = parser.Int("", "start_year", &argparse.Options{
+		Default: 2011,
+		Help:    "出力する気象データの開始年（標準年データの検討期間も兼ねる）"})
+
+	site.endYear = parser.Int("", "end_year", &argparse.Options{
+		Default: 2020,
+		Help:    "出力する気象データの終了年（標準年データの検討期間も兼ねる）"})
+
+	site.mode = parser.Selector("", "mode", []string{"normal", "EA"}, &argparse.Options{
+		Default: "normal",
+		Help:    "計算モードの指定 標準=normal(デフォルト), 標準年=EA"})
+
+	site.modeEle = parser.Selector("", "mode_elevation", []string{"mesh", "api"}, &argparse.Options{
+		Default: "api",
+		Help:    "標高判定方法 API=api(デフォルト), メッシュデータ=mesh"})
+
+	site.disableEst = parser.Flag("", "disable_est", &argparse.Options{
+		Help: "標準年データの検討に日射量の推計値を使用しない（使用しない場合2018年以降のデータのみで作成）"})
+
+	site.msmFileDir = parser.String("", "msm_file_dir", &argparse.Options{
+		Default: ".msm_cache",
+		Help:    "MSMファイルの格納ディレクトリ"})
+
+	site.modeSep = parser.Selector("", "mode_separate", []string{"Nagata", "Watanabe", "Erbs", "Udagawa", "Perez"}, &argparse.Options{
+		Default: "Perez",
+		Help:    "直散分離の方法"})
+
+	site.horizon = parser.String("", "horizon", &argparse.Options{
+		Default: "none",
+		Help:    "地形による日射の遮蔽 考慮しない=none(デフォルト), 3次メッシュの標高から計算=mesh, それ以外は地平線の仰角(方位角,仰角)を記述したCSVファイルのパス"})
+
+	site.windMode = parser.Selector("", "wind_mode", []string{arcclimate.WindMode16, arcclimate.WindModeContinuous}, &argparse.Options{
+		Default: arcclimate.WindMode16,
+		Help:    "風向風速の計算方法 16方位=16(デフォルト), 連続的な風向とベクトルの大きさ=continuous"})
+
+	return site
+}
+
+// 引数に従って推計対象地点の気象データを作成します。
+// 晴天日射量を計算する場合は modeClearSky に "Ineichen" または "Bird" を指定します。
+// 設計用外気条件を計算する場合は designConditions = true とします。
+func (site *siteArguments) interpolate(modeClearSky string, designConditions bool) *arcclimate.MsmTarget {
+	return site.interpolateWithOptions(arcclimate.InterpolateOptions{
+		ModeClearSky:     modeClearSky,
+		DesignConditions: designConditions,
+	})
+}
+
+// 引数とオプション opts に従って推計対象地点の気象データを作成します。
+// 地形による日射の遮蔽と風向風速の計算方法は引数の値とします。
+func (site *siteArguments) interpolateWithOptions(opts arcclimate.InterpolateOptions) *arcclimate.MsmTarget {
+	// MSMフォルダの作成
+	// os.MkdirAll(*msmFileDir, os.ModePerm)
+
+	// EA方式かつ日射量の推計値を使用しない場合に開始年が2018年以上となっているか確認
+	if *site.mode == "EA" {
+		if *site.disableEst {
+			if *site.startYear < 2018 {
+				log.Printf("--disable_estを設定した場合は開始年を2018年以降にする必要があります")
+				fmt.Fprintln(os.Stderr, "Error: If \"disable_est\" is set, the start year must be 2018 or later")
+				os.Exit(1)
+			} else {
+				*site.disableEst = false
+			}
+		}
+	}
+
+	opts.Horizon = *site.horizon
+	opts.WindMode = *site.windMode
+
+	// 補間処理 (0.3s)
+	return arcclimate.InterpolateWithOptions(
+		*site.lat,
+		*site.lon,
+		*site.startYear,
+		*site.endYear,
+		*site.modeEle,
+		*site.mode,
+		!*site.disableEst,
+		*site.modeSep,
+		false,
+		false,
+		*site.msmFileDir,
+		opts,
+	)
+}
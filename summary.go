@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/akamensky/argparse"
+)
+
+// summary コマンド: 作成した気象データの月別・年別の気象概況を出力します。
+func runSummary(args []string) {
+	parser := argparse.NewParser("summary", "Summarizes the interpolated meteorological data by month and year")
+
+	site := addSiteArguments(parser)
+
+	format := parser.Selector("f", "format", []string{"CSV", "JSON", "Markdown"}, &argparse.Options{
+		Default: "CSV",
+		Help:    "出力形式 CSV, JSON or Markdown"})
+
+	filename := parser.String("o", "output", &argparse.Options{
+		Default: "",
+		Help:    "気象概況の保存ファイルパス(省略時は標準出力)"})
+
+	err := parser.Parse(args)
+	if err != nil {
+		fmt.Print(parser.Usage(err))
+		os.Exit(1)
+	}
+
+	// 補間処理
+	res := site.interpolate("none", false)
+
+	// 気象概況の計算
+	log.Printf("気象概況の計算")
+	summary := res.Summarize()
+
+	// 保存
+	var buf *bytes.Buffer = bytes.NewBuffer([]byte{})
+	switch *format {
+	case "CSV":
+		summary.ToCSV(buf)
+	case "JSON":
+		summary.ToJSON(buf)
+	case "Markdown":
+		summary.ToMarkdown(buf)
+	}
+	if *filename == "" {
+		fmt.Print(buf.String())
+	} else {
+		log.Printf("気象概況の保存: %s", *filename)
+		err := os.WriteFile(*filename, buf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	log.Printf("計算が終了しました")
+}
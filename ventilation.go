@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/akamensky/argparse"
+	"github.com/udawtr/arcclimate-go/arcclimate"
+)
+
+// ventilation コマンド: 作成した気象データから月別・年別の自然換気・夜間換気の利用可能性を出力します。
+func runVentilation(args []string) {
+	parser := argparse.NewParser("ventilation", "Calculates natural ventilation hours and night-time cooling potential by month and year from the interpolated meteorological data")
+
+	site := addSiteArguments(parser)
+
+	def := arcclimate.DefaultVentilationConfig
+
+	tmpMin := parser.Float("", "tmp_min", &argparse.Options{
+		Default: def.TMPMin,
+		Help:    "自然換気に適する気温の下限 [℃]"})
+
+	tmpMax := parser.Float("", "tmp_max", &argparse.Options{
+		Default: def.TMPMax,
+		Help:    "自然換気に適する気温の上限 [℃]"})
+
+	dtMax := parser.Float("", "dt_max", &argparse.Options{
+		Default: def.DTMax,
+		Help:    "自然換気に適する露点温度の上限 [℃]"})
+
+	rainMax := parser.Float("", "rain_max", &argparse.Options{
+		Default: def.RainMax,
+		Help:    "自然換気に適する降水量の上限 [mm/h] (0の場合は降水なし)"})
+
+	windMin := parser.Float("", "wind_min", &argparse.Options{
+		Default: def.WindMin,
+		Help:    "自然換気に適する風速の下限 [m/s]"})
+
+	windMax := parser.Float("", "wind_max", &argparse.Options{
+		Default: def.WindMax,
+		Help:    "自然換気に適する風速の上限 [m/s]"})
+
+	setpoint := parser.Float("", "setpoint", &argparse.Options{
+		Default: def.Setpoint,
+		Help:    "夜間換気の室内設定温度 [℃]"})
+
+	nightHours := parser.String("", "night_hours", &argparse.Options{
+		Default: "22-6",
+		Help:    "夜間換気の時刻 ex) 22-6 or 0-5,23"})
+
+	nightDelta := parser.Float("", "night_delta", &argparse.Options{
+		Default: def.NightDelta,
+		Help:    "夜間換気に必要な室内設定温度と気温の差の下限 [K]"})
+
+	format := parser.Selector("f", "format", []string{"CSV", "JSON"}, &argparse.Options{
+		Default: "CSV",
+		Help:    "出力形式 CSV or JSON"})
+
+	filename := parser.String("o", "output", &argparse.Options{
+		Default: "",
+		Help:    "自然換気の利用可能性の保存ファイルパス(省略時は標準出力)"})
+
+	err := parser.Parse(args)
+	if err != nil {
+		fmt.Print(parser.Usage(err))
+		os.Exit(1)
+	}
+
+	// 判定条件
+	cfg := arcclimate.VentilationConfig{
+		TMPMin:     *tmpMin,
+		TMPMax:     *tmpMax,
+		DTMax:      *dtMax,
+		RainMax:    *rainMax,
+		WindMin:    *windMin,
+		WindMax:    *windMax,
+		Setpoint:   *setpoint,
+		NightDelta: *nightDelta,
+	}
+	if cfg.TMPMin > cfg.TMPMax || cfg.WindMin > cfg.WindMax {
+		fmt.Fprintln(os.Stderr, "Error: the lower limit must not exceed the upper limit")
+		os.Exit(1)
+	}
+	cfg.NightHours, err = arcclimate.ParseIntRanges(*nightHours, 0, 23)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+
+	// 補間処理
+	res := site.interpolate("none", false)
+
+	// 自然換気の利用可能性の計算
+	log.Printf("自然換気の利用可能性の計算")
+	ventilation := res.CalcVentilation(cfg)
+
+	// 保存
+	var buf *bytes.Buffer = bytes.NewBuffer([]byte{})
+	switch *format {
+	case "CSV":
+		ventilation.ToCSV(buf)
+	case "JSON":
+		ventilation.ToJSON(buf)
+	}
+	if *filename == "" {
+		fmt.Print(buf.String())
+	} else {
+		log.Printf("自然換気の利用可能性の保存: %s", *filename)
+		err := os.WriteFile(*filename, buf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	log.Printf("計算が終了しました")
+}
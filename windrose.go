@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/akamensky/argparse"
+	"github.com/udawtr/arcclimate-go/arcclimate"
+)
+
+// windrose コマンド: 作成した気象データから風配図と風の統計を作成します。
+func runWindRose(args []string) {
+	parser := argparse.NewParser("windrose", "Creates a wind rose and wind statistics from the interpolated meteorological data")
+
+	site := addSiteArguments(parser)
+
+	seasonNames := []string{"Annual"}
+	for _, s := range arcclimate.Seasons {
+		seasonNames = append(seasonNames, s.Name)
+	}
+	season := parser.Selector("", "season", seasonNames, &argparse.Options{
+		Default: "Annual",
+		Help:    "対象の季節 Annual(デフォルト), Summer, Winter, Autumn or Spring"})
+
+	months := parser.String("", "months", &argparse.Options{
+		Default: "",
+		Help:    "対象の月 ex) 6,7,8 or 12-2 (指定した場合は --season より優先)"})
+
+	hours := parser.String("", "hours", &argparse.Options{
+		Default: "",
+		Help:    "対象の時刻 ex) 9-17 or 22-5 (省略時は全時刻)"})
+
+	calm := parser.Float("", "calm", &argparse.Options{
+		Default: arcclimate.DefaultCalmSpeed,
+		Help:    "静穏とする風速 [m/s] (この値未満を静穏とします)"})
+
+	speedClasses := parser.String("", "speed_classes", &argparse.Options{
+		Default: "1,2,3,4,6,8",
+		Help:    "風速階級の境界 [m/s] カンマ区切り"})
+
+	format := parser.Selector("f", "format", []string{"CSV", "JSON"}, &argparse.Options{
+		Default: "CSV",
+		Help:    "出力形式 CSV or JSON"})
+
+	filename := parser.String("o", "output", &argparse.Options{
+		Default: "",
+		Help:    "風配図の保存ファイルパス(省略時は標準出力)"})
+
+	svgFilename := parser.String("", "svg", &argparse.Options{
+		Default: "",
+		Help:    "風配図のSVGの保存ファイルパス"})
+
+	err := parser.Parse(args)
+	if err != nil {
+		fmt.Print(parser.Usage(err))
+		os.Exit(1)
+	}
+
+	// 集計条件
+	cfg := arcclimate.WindRoseConfig{Calm: *calm}
+	for _, item := range strings.Split(*speedClasses, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(item), 64)
+		if err != nil || !(v > *calm) || (len(cfg.SpeedClasses) > 0 && v <= cfg.SpeedClasses[len(cfg.SpeedClasses)-1]) {
+			fmt.Fprintf(os.Stderr, "Error: invalid speed classes %q\n", *speedClasses)
+			os.Exit(1)
+		}
+		cfg.SpeedClasses = append(cfg.SpeedClasses, v)
+	}
+	for _, s := range arcclimate.Seasons {
+		if s.Name == *season {
+			cfg.Months = s.Months
+		}
+	}
+	if *months != "" {
+		cfg.Months, err = arcclimate.ParseIntRanges(*months, 1, 12)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+	}
+	cfg.Hours, err = arcclimate.ParseIntRanges(*hours, 0, 23)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+
+	// 補間処理
+	res := site.interpolate("none", false)
+
+	// 風配図の作成
+	log.Printf("風配図の作成")
+	wr := res.CalcWindRose(cfg)
+
+	// 保存
+	var buf *bytes.Buffer = bytes.NewBuffer([]byte{})
+	if *format == "CSV" {
+		wr.ToCSV(buf)
+	} else {
+		wr.ToJSON(buf)
+	}
+	if *filename == "" {
+		fmt.Print(buf.String())
+	} else {
+		log.Printf("風配図の保存: %s", *filename)
+		err := os.WriteFile(*filename, buf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	if *svgFilename != "" {
+		log.Printf("風配図のSVGの保存: %s", *svgFilename)
+		title := fmt.Sprintf("%.3f, %.3f %s", *site.lat, *site.lon, *season)
+		if *months != "" {
+			title = fmt.Sprintf("%.3f, %.3f months %s", *site.lat, *site.lon, *months)
+		}
+		if *hours != "" {
+			title += " hours " + *hours
+		}
+		var sbuf *bytes.Buffer = bytes.NewBuffer([]byte{})
+		wr.ToSVG(sbuf, title)
+		err := os.WriteFile(*svgFilename, sbuf.Bytes(), os.ModePerm)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	log.Printf("計算が終了しました")
+}